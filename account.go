@@ -3,22 +3,18 @@ package beth
 import (
 	"context"
 	"crypto/ecdsa"
-	"encoding/json"
 	"errors"
 	"fmt"
 	"math"
 	"math/big"
-	"net/http"
-	"strings"
 	"sync"
 	"time"
 
+	ethereum "github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
-	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/types"
-	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/ethclient"
 )
 
@@ -42,6 +38,10 @@ var ErrDuplicateAddress = errors.New("the key has already been mapped to another
 // address book.
 var ErrAddressNotFound = errors.New("key does not have an entry in the address book")
 
+// PostConditionTimeout is the time for which Transact polls the post-condition
+// check after every attempt to execute a transaction.
+const PostConditionTimeout = 3 * time.Minute
+
 // The TxExecutionSpeed indicates the tier of speed that the transaction falls
 // under while writing to the blockchain.
 type TxExecutionSpeed uint8
@@ -55,6 +55,59 @@ const (
 	Fastest
 )
 
+// TransactAttempt is a single attempt by Transact to execute a transaction.
+type TransactAttempt struct {
+
+	// Hash of the transaction that was sent. It is the zero hash if the
+	// attempt failed before a transaction was sent.
+	Hash common.Hash
+
+	// Err returned by the attempt, or nil if the transaction was mined.
+	Err error
+}
+
+// TransactResult is the outcome of a transaction executed by Transact.
+type TransactResult struct {
+
+	// Transaction that was mined. If the original transaction was replaced,
+	// this is the replacement that was mined. It is nil if the post-condition
+	// check passed without any transaction being mined, in which case only
+	// the attempts are set.
+	Transaction *types.Transaction
+
+	// Receipt of the mined transaction.
+	Receipt *types.Receipt
+
+	// Status of the mined transaction, which is types.ReceiptStatusFailed if
+	// the transaction reverted.
+	Status uint64
+
+	// GasUsed by the mined transaction.
+	GasUsed uint64
+
+	// EffectiveGasPrice is the price (in wei) paid per unit of gas used by the
+	// mined transaction.
+	EffectiveGasPrice *big.Int
+
+	// BlockNumber of the block that the transaction was mined in.
+	BlockNumber *big.Int
+
+	// BlockHash of the block that the transaction was mined in.
+	BlockHash common.Hash
+
+	// Confirmations is the number of blocks that had been mined after the
+	// block of the transaction when Transact returned.
+	Confirmations uint64
+
+	// Replacements contains the hashes of all replacement transactions that
+	// were sent, in the order that they were sent.
+	Replacements []common.Hash
+
+	// Attempts contains every attempt to execute the transaction, in the order
+	// that they were made.
+	Attempts []TransactAttempt
+}
+
 // Account is an Ethereum external account that can submit write transactions
 // to the Ethereum blockchain. An Account is defined by its public address and
 // respective private key.
@@ -64,14 +117,20 @@ type Account interface {
 	// operations can be executed on Ethereum.
 	Client() Client
 
-	// EthClient returns the actual Ethereum client.
+	// EthClient returns the actual Ethereum client. It returns nil if the
+	// account was created with a Backend that is not an *ethclient.Client.
 	EthClient() *ethclient.Client
 
+	// Backend returns the backend that the account reads from and sends its
+	// transactions to.
+	Backend() Backend
+
 	// Address returns the ethereum address of the account holder.
 	Address() common.Address
 
 	// BalanceAt returns the wei balance of the account. The block number can be
-	// nil, in which case the balance is taken from the latest known block.
+	// nil, in which case the balance is taken from the block carried by the
+	// context, which is the latest block by default.
 	BalanceAt(ctx context.Context, blockNumber *big.Int) (*big.Int, error)
 
 	// Store address in address book.
@@ -80,28 +139,89 @@ type Account interface {
 	// ReadAddress returns address mapped to the given key in the address book.
 	ReadAddress(key string) (common.Address, error)
 
-	// Transfer sends the specified value of Eth to the given address.
+	// Transfer sends the specified value of Eth to the given address. The
+	// number of blocks to wait for can be WaitForSafe or WaitForFinalized.
 	Transfer(ctx context.Context, to common.Address, value, gasPrice *big.Int, confirmBlocks int64, sendAll bool) (*types.Transaction, error)
 
 	// Transact performs a write operation on the Ethereum blockchain. It will
 	// first conduct a preConditionCheck and if the check passes, it will
 	// repeatedly execute the transaction followed by a postConditionCheck,
-	// until the transaction passes and the postConditionCheck returns true.
-	// Transact will immediately stop retrying if an ErrReplaceUnderpriced is
-	// returned from ethereum.
+	// until the transaction passes and the postConditionCheck returns true. A
+	// nil postConditionCheck only passes once the transaction is mined.
+	// Transact will immediately stop retrying if an ErrReplacementUnderpriced,
+	// or any other error that is not retryable, is returned from ethereum. Once
+	// the transaction is mined, Transact waits for confirmBlocks blocks, or
+	// until the block of the transaction is safe or finalized if confirmBlocks
+	// is WaitForSafe or WaitForFinalized.
 	Transact(ctx context.Context, preConditionCheck func() bool, f func(*bind.TransactOpts) (*types.Transaction, error), postConditionCheck func() bool, confirmBlocks int64) (*types.Transaction, error)
 
-	// Sign the given message with the account's private key.
+	// TransactWithResult performs a write operation in the same way as
+	// Transact, but returns a TransactResult that describes the execution of
+	// the transaction. If the postConditionCheck passes without any
+	// transaction being mined, for example because the attempts failed after
+	// someone else made the change, the result has a nil Transaction and
+	// Receipt, and Transact returns a nil transaction and a nil error. Unless
+	// the preConditionCheck fails, the result of the attempts made so far is
+	// returned along with any error.
+	TransactWithResult(ctx context.Context, preConditionCheck func() bool, f func(*bind.TransactOpts) (*types.Transaction, error), postConditionCheck func() bool, confirmBlocks int64) (*TransactResult, error)
+
+	// Sign the given message hash with the signer of the account. Signers
+	// that cannot sign raw hashes, such as remote signers, return
+	// ErrSignHashNotSupported.
 	Sign(msgHash []byte) ([]byte, error)
 
+	// Signer returns the signer of the transactions and messages of the
+	// account.
+	Signer() Signer
+
 	// SetGasPrice allows the account holder to set the gasPrice to a specific
 	// value.
 	SetGasPrice(gasPrice float64)
 
+	// SetFeeOracle allows the account holder to send EIP-1559 dynamic-fee
+	// transactions priced by the given oracle. Setting a nil oracle reverts the
+	// account to legacy transactions.
+	SetFeeOracle(feeOracle FeeOracle)
+
+	// SetLogger sets the Logger that receives the events of the account and
+	// its client. Setting a nil logger discards all events.
+	SetLogger(logger Logger)
+
+	// SetMetrics sets the Metrics that collect measurements of the account
+	// and its client. Setting nil metrics discards all measurements.
+	SetMetrics(metrics *Metrics)
+
+	// SetRevertPolicy allows the account holder to choose whether Transact
+	// retries transactions that are mined, but revert. By default, Transact
+	// returns an ErrReverted as soon as a transaction reverts. Transactions
+	// that run out of gas are always retried, with a new gas estimate.
+	SetRevertPolicy(policy RevertPolicy)
+
+	// SetReplacementPolicy allows the account holder to replace transactions
+	// that are not mined in time with transactions that have a higher gas
+	// price and the same nonce. Setting a nil policy disables replacements.
+	SetReplacementPolicy(policy *ReplacementPolicy)
+
+	// SetRetryPolicy sets the RetryPolicy used by Transact and by the reads of
+	// the account and its client. Setting a nil policy uses the
+	// DefaultRetryPolicy. The policy can be overridden for a single call using
+	// WithRetryPolicy.
+	SetRetryPolicy(policy *RetryPolicy)
+
+	// SetBatchSize sets the number of reads that batched reads of the account
+	// and its client send in a single JSON-RPC batch. Setting a size that is
+	// not positive uses the DefaultBatchSize.
+	SetBatchSize(size int)
+
 	// ResetToPendingNonce will wait for a 'coolDown' time (in milliseconds)
 	// before updating transaction nonce to current pending nonce.
 	ResetToPendingNonce(ctx context.Context, coolDown time.Duration) error
 
+	// ConfirmationTracker returns the tracker that Transact uses to wait for
+	// the confirmations of transactions. It can be used to follow the
+	// confirmations of any transaction mined on the chain of the account.
+	ConfirmationTracker() ConfirmationTracker
+
 	// FormatTransactionView returns the formatted string with the URL at which
 	// the transaction can be viewed.
 	FormatTransactionView(msg, txHash string) (string, error)
@@ -115,37 +235,76 @@ type account struct {
 
 	callOpts     *bind.CallOpts
 	transactOpts *bind.TransactOpts
+	nonces       NonceManager
+
+	confirmations ConfirmationTracker
+
+	signer  Signer
+	chainID *big.Int
+
+	gasPriceOracle GasPriceOracle
+	feeOracle      FeeOracle
 
-	privateKey *ecdsa.PrivateKey
+	replacementPolicy *ReplacementPolicy
+	revertPolicy      RevertPolicy
+	retryPolicy       *RetryPolicy
+
+	logger  Logger
+	metrics *Metrics
 
 	addressBook AddressBook
 }
 
 // NewAccount returns a user account for the provided private key which is
-// connected to an Ethereum client.
-func NewAccount(url string, privateKey *ecdsa.PrivateKey) (Account, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
-	defer cancel()
-
+// connected to an Ethereum client. The gas price of every transaction is
+// taken from the gasPriceOracle. If the oracle is nil, gas prices are taken
+// from ethGasStation.
+func NewAccount(url string, privateKey *ecdsa.PrivateKey, gasPriceOracle GasPriceOracle) (Account, error) {
 	// Connect to client
 	client, err := Connect(url)
 	if err != nil {
 		return nil, err
 	}
+	return NewAccountWithSigner(client, NewPrivateKeySigner(privateKey), gasPriceOracle)
+}
+
+// NewAccountWithBackend returns a user account for the provided private key
+// which reads from, and sends its transactions to, the backend. The client of
+// the account has no JSON-RPC endpoint, as described by NewClient. The gas
+// price of every transaction is taken from the gasPriceOracle. If the oracle
+// is nil, gas prices are taken from ethGasStation.
+func NewAccountWithBackend(backend Backend, privateKey *ecdsa.PrivateKey, gasPriceOracle GasPriceOracle) (Account, error) {
+	client, err := NewClient(backend)
+	if err != nil {
+		return nil, err
+	}
+	return NewAccountWithSigner(client, NewPrivateKeySigner(privateKey), gasPriceOracle)
+}
+
+// NewAccountWithSigner returns a user account which is connected to the client,
+// and whose transactions and messages are signed by the signer. The gas price
+// of every transaction is taken from the gasPriceOracle. If the oracle is nil,
+// gas prices are taken from ethGasStation.
+func NewAccountWithSigner(client Client, signer Signer, gasPriceOracle GasPriceOracle) (Account, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
 
 	// Setup transact opts
-	transactOpts := bind.NewKeyedTransactor(privateKey)
-	nonce, err := client.EthClient().PendingNonceAt(ctx, transactOpts.From)
+	chainID, err := client.Backend().ChainID(ctx)
 	if err != nil {
 		return nil, err
 	}
-	transactOpts.Nonce = big.NewInt(0).SetUint64(nonce)
+	transactOpts := &bind.TransactOpts{From: signer.Address()}
 
-	netID, err := client.ethClient.NetworkID(ctx)
+	netID, err := client.Backend().NetworkID(ctx)
 	if err != nil {
 		return nil, err
 	}
 
+	if gasPriceOracle == nil {
+		gasPriceOracle = NewEthGasStationOracle()
+	}
+
 	// Create account
 	account := &account{
 		mu:     new(sync.RWMutex),
@@ -154,11 +313,23 @@ func NewAccount(url string, privateKey *ecdsa.PrivateKey) (Account, error) {
 		callOpts:     new(bind.CallOpts),
 		transactOpts: transactOpts,
 
-		privateKey: privateKey,
+		signer:  signer,
+		chainID: chainID,
+
+		gasPriceOracle: gasPriceOracle,
+
+		logger: NopLogger(),
 
 		addressBook: DefaultAddressBook(netID.Int64()),
 	}
 
+	// The nonce manager and the confirmation tracker share the client of the
+	// account, so that they see its logger, metrics and retry policy
+	if account.nonces, err = newNonceManager(ctx, &account.client, transactOpts.From); err != nil {
+		return nil, err
+	}
+	account.confirmations = newConfirmationTracker(&account.client, DefaultConfirmationPollInterval)
+
 	return account, nil
 }
 
@@ -168,9 +339,12 @@ func (account *account) Address() common.Address {
 }
 
 // BalanceAt returns the wei balance of the account. The block number can be nil,
-// in which case the balance is taken from the latest known block.
+// in which case the balance is taken from the block carried by the context.
 func (account *account) BalanceAt(ctx context.Context, blockNumber *big.Int) (*big.Int, error) {
-	return account.client.ethClient.BalanceAt(ctx, account.Address(), nil)
+	if blockNumber != nil {
+		ctx = WithBlock(ctx, BlockAtNumber(blockNumber))
+	}
+	return account.client.BalanceOf(ctx, account.Address())
 }
 
 // WriteAddress to the address book, overwrite if already exists
@@ -195,95 +369,211 @@ func (account *account) EthClient() *ethclient.Client {
 	return account.client.EthClient()
 }
 
+func (account *account) Backend() Backend {
+	return account.client.Backend()
+}
+
 // Transact attempts to execute a transaction on the Ethereum blockchain with
 // the retry functionality. It stops retrying if tx is completed without any
-// error, or if given context times-out, or if ErrReplaceUnderpriced is
-// returned from Ethereum.
+// error, or if given context times-out, or if ErrReplacementUnderpriced or any
+// other error that is not retryable is returned from Ethereum.
 func (account *account) Transact(ctx context.Context, preConditionCheck func() bool, f func(*bind.TransactOpts) (*types.Transaction, error), postConditionCheck func() bool, waitForBlocks int64) (*types.Transaction, error) {
+	result, err := account.TransactWithResult(ctx, preConditionCheck, f, postConditionCheck, waitForBlocks)
+	if err != nil {
+		return nil, err
+	}
+	return result.Transaction, nil
+}
+
+// TransactWithResult attempts to execute a transaction on the Ethereum
+// blockchain in the same way as Transact, and returns a TransactResult that
+// describes the execution of the transaction. Unless the pre-condition check
+// fails, the result is returned along with any error, so that the attempts
+// made so far can be inspected. For example, if the transaction reverts and the
+// account does not retry reverted transactions, the result is returned along
+// with an ErrReverted.
+func (account *account) TransactWithResult(ctx context.Context, preConditionCheck func() bool, f func(*bind.TransactOpts) (*types.Transaction, error), postConditionCheck func() bool, waitForBlocks int64) (*TransactResult, error) {
 
 	// Do not proceed any further if the (not nil) pre-condition check fails
 	if preConditionCheck != nil && !preConditionCheck() {
 		return nil, ErrPreConditionCheckFailed
 	}
 
-	sleepDurationMs := time.Duration(1000)
-	var txHash common.Hash
-	var transction *types.Transaction
+	account.mu.RLock()
+	policy := retryPolicy(ctx, account.retryPolicy)
+	account.mu.RUnlock()
+
+	var firstSentAt time.Time
+	result := &TransactResult{
+		Replacements: []common.Hash{},
+		Attempts:     []TransactAttempt{},
+	}
 
-	// Keep retrying 'f' until the post-condition check passes or the context
-	// times out.
+	// Keep retrying 'f' until the post-condition check passes, the retry
+	// policy stops retrying or the context times out.
 	var postConPassed = false
-	for !postConPassed {
+	for attempt := 1; !postConPassed; attempt++ {
 		// If context is done, return error
 		select {
 		case <-ctx.Done():
-			return nil, ctx.Err()
+			return result, ctx.Err()
 		default:
 		}
 
-		if err := func() error {
-			account.mu.Lock()
-			defer account.mu.Unlock()
+		attemptErr := func() error {
+			account.updateGasPrice(ctx, Fast)
 
-			account.updateGasPrice(Fast)
 			// This will attempt to execute 'f' until no nonce error is
 			// returned or if ctx times out
 			innerCtx, innerCancel := context.WithTimeout(ctx, 10*time.Minute)
 			defer innerCancel()
 
+			account.getMetrics().transactAttempt(len(result.Attempts) + 1)
 			tx, err := account.retryNonceTx(innerCtx, f)
 			if err != nil {
+				attempt := TransactAttempt{Err: err}
+				if tx != nil {
+					attempt.Hash = tx.Hash()
+				}
+				result.Attempts = append(result.Attempts, attempt)
 				return err
 			}
-
-			if _, err := account.client.WaitMined(innerCtx, tx); err != nil {
+			sentAt := time.Now()
+			if firstSentAt.IsZero() {
+				firstSentAt = sentAt
+			}
+			account.log().Debug("transaction sent", "hash", tx.Hash(), "nonce", tx.Nonce(), "attempt", len(result.Attempts)+1, "gasPrice", tx.GasPrice(), "gasFeeCap", tx.GasFeeCap(), "gasTipCap", tx.GasTipCap())
+
+			// Wait for the transaction, or one of its replacements, to be
+			// mined. The account is not locked while waiting, so other
+			// transactions can be sent in the meantime. Transactions that are
+			// replaced keep being replaced, with the same nonce, until one of
+			// them is mined or the context is done, so the wait is not
+			// bounded by the timeout of the attempt.
+			waitCtx := innerCtx
+			account.mu.RLock()
+			if account.replacementPolicy != nil {
+				waitCtx = ctx
+			}
+			account.mu.RUnlock()
+			receipt, minedTx, sent, err := account.waitMined(waitCtx, tx)
+			for _, replacement := range sent[1:] {
+				result.Replacements = append(result.Replacements, replacement.Hash())
+			}
+			if err == nil && receipt.Status == types.ReceiptStatusFailed {
+				if receipt.GasUsed == minedTx.Gas() {
+					err = ErrOutOfGas
+				} else {
+					err = account.revertError(waitCtx, minedTx, receipt)
+				}
+			}
+			result.Attempts = append(result.Attempts, TransactAttempt{Hash: tx.Hash(), Err: err})
+			if _, ok := err.(*ErrReverted); ok || err == ErrOutOfGas {
+				// The nonce of a reverted transaction has still been used
+				account.nonces.Done(minedTx.Nonce())
+				result.setReceipt(minedTx, receipt)
+				account.getMetrics().revert()
+				account.log().Warn("transaction reverted", "hash", minedTx.Hash(), "nonce", minedTx.Nonce(), "block", receipt.BlockNumber, "err", err)
+				return err
+			}
+			if err != nil {
+				// If the node no longer knows about the transaction, or any
+				// of its replacements, it has been dropped and its nonce can
+				// be re-used
+				if account.dropped(sent) {
+					account.log().Warn("transaction dropped", "hash", tx.Hash(), "nonce", tx.Nonce())
+					account.nonces.Release(tx.Nonce())
+				}
 				return err
 			}
-			txHash = tx.Hash()
-			transction = tx
+			account.nonces.Done(minedTx.Nonce())
+			result.setReceipt(minedTx, receipt)
+			account.getMetrics().mined(sentAt)
+			account.log().Info("transaction mined", "hash", minedTx.Hash(), "nonce", minedTx.Nonce(), "block", receipt.BlockNumber, "gasUsed", receipt.GasUsed)
+			if gasPrice, err := account.effectiveGasPrice(ctx, minedTx, receipt); err == nil {
+				result.EffectiveGasPrice = gasPrice
+			}
 
 			// Transaction did not error, proceed to post-condition checks
 			return nil
-		}(); err != nil {
+		}()
+		if err := ClassifyError(attemptErr); err != nil {
+
 			// There is another transaction with the same nonce and a higher or
 			// equal gas price as that of this transaction.
-			if strings.Compare(err.Error(), core.ErrReplaceUnderpriced.Error()) == 0 {
-				return nil, ErrNonceIsOutOfSync
+			if errors.Is(err, ErrReplacementUnderpriced) {
+				return result, ErrNonceIsOutOfSync
 			}
-			fmt.Println(err)
-		}
 
-		for i := 0; i < 180; i++ {
-			select {
-			case <-ctx.Done():
-				return nil, ErrPostConditionCheckFailed
-			default:
-				if postConditionCheck == nil || postConditionCheck() {
-					postConPassed = true
+			// Sending the transaction again cannot succeed, or the retry
+			// policy does not allow it
+			if !IsRetryable(err) || !policy.retryable(err) {
+				return result, err
+			}
+
+			// The transaction reverted, so only retry it if the account
+			// allows reverted transactions to be retried
+			var reverted *ErrReverted
+			if errors.As(err, &reverted) {
+				account.mu.RLock()
+				revertPolicy := account.revertPolicy
+				account.mu.RUnlock()
+				if revertPolicy == FailOnRevert {
+					return result, err
 				}
 			}
-			if postConPassed {
+			account.log().Warn("transaction attempt failed", "attempt", len(result.Attempts), "hash", result.Attempts[len(result.Attempts)-1].Hash, "err", err, "class", errorClass(err))
+		}
+
+		// Poll the post-condition check, backing off according to the retry
+		// policy, until it passes or the post-condition timeout elapses. A
+		// transaction that was mined, but failed, cannot make the
+		// post-condition pass, so it is only checked once before retrying.
+		postConDeadline := time.Now().Add(PostConditionTimeout)
+		var reverted *ErrReverted
+		if errors.Is(attemptErr, ErrOutOfGas) || errors.As(attemptErr, &reverted) {
+			postConDeadline = time.Now()
+		}
+		for poll := 1; ; poll++ {
+			if postConditionCheck == nil {
+				// Without a post-condition check, the transaction must have
+				// been mined
+				postConPassed = attemptErr == nil
 				break
 			}
-			time.Sleep(time.Second)
+			if postConditionCheck() {
+				postConPassed = true
+				break
+			}
+			if time.Now().After(postConDeadline) {
+				break
+			}
+			select {
+			case <-ctx.Done():
+				return result, ErrPostConditionCheckFailed
+			case <-time.After(policy.delay(poll)):
+			}
+		}
+		if postConPassed {
+			break
 		}
 
-		// If post-condition check passes, proceed to wait for a specified
-		// number of blocks to be confirmed after the transaction's block
+		// The post-condition check failed, so stop if the retry policy allows
+		// no more attempts
+		if policy.exhausted(attempt) {
+			if attemptErr != nil {
+				return result, ClassifyError(attemptErr)
+			}
+			return result, ErrPostConditionCheckFailed
+		}
 
 		// Wait for sometime before attempting to execute the transaction
 		// again. If context is done, return error to indicate that
 		// post-condition failed
 		select {
 		case <-ctx.Done():
-			return nil, ErrPostConditionCheckFailed
-		case <-time.After(sleepDurationMs * time.Millisecond):
-		}
-
-		// Increase delay for next round but saturate at 30s
-		sleepDurationMs = time.Duration(float64(sleepDurationMs) * 1.6)
-		if sleepDurationMs > 30000 {
-			sleepDurationMs = 30000
+			return result, ErrPostConditionCheckFailed
+		case <-time.After(policy.delay(attempt)):
 		}
 	}
 
@@ -292,40 +582,80 @@ func (account *account) Transact(ctx context.Context, preConditionCheck func() b
 	// wait for a pre-defined number of blocks to be confirmed on the
 	// blockchain after the transaction's block is confirmed
 
-	// Attempt to get block number of transaction. If context times out, an
-	// error will be returned.
-	blockNumber, err := account.client.TxBlockNumber(ctx, txHash.String())
-	if err != nil {
-		return nil, err
+	// The post-condition can pass without any of the transactions being mined,
+	// in which case there are no blocks to wait for
+	if result.Transaction == nil {
+		return result, nil
 	}
 
-	// Attempt to get current block number. If context times out, an error will
-	// be returned.
-	currentBlockNumber, err := account.client.CurrentBlockNumber(ctx)
+	// Wait until the current block number is at least 'waitForBlocks' greater
+	// than the block number of the transaction in the canonical chain, or
+	// until its block is safe or finalized. If the transaction is reorged, the
+	// result is updated with its new receipt. If context times out, the error
+	// is returned.
+	confirmed := false
+	for event := range account.confirmations.TrackTransaction(ctx, result.Transaction, result.Receipt, waitForBlocks) {
+		if event.Err != nil {
+			return result, event.Err
+		}
+		if event.Status == Reorged {
+			// The base fee, and so the effective gas price, can differ in
+			// the new block of the transaction
+			result.setReceipt(result.Transaction, event.Receipt)
+			if gasPrice, err := account.effectiveGasPrice(ctx, result.Transaction, event.Receipt); err == nil {
+				result.EffectiveGasPrice = gasPrice
+			}
+		}
+		result.Confirmations = event.Confirmations
+		confirmed = event.Confirmed
+		account.log().Debug("transaction confirmed", "hash", result.Transaction.Hash(), "status", event.Status, "confirmations", event.Confirmations, "required", waitForBlocks)
+	}
+	if !confirmed {
+		return result, ctx.Err()
+	}
+	account.getMetrics().confirmed(firstSentAt)
+	return result, nil
+}
+
+// setReceipt sets the mined transaction, and the details of its receipt, in
+// the result.
+func (result *TransactResult) setReceipt(tx *types.Transaction, receipt *types.Receipt) {
+	result.Transaction = tx
+	result.Receipt = receipt
+	if receipt == nil {
+		return
+	}
+	result.Status = receipt.Status
+	result.GasUsed = receipt.GasUsed
+	result.BlockNumber = receipt.BlockNumber
+	result.BlockHash = receipt.BlockHash
+}
+
+// effectiveGasPrice returns the price paid per unit of gas by a mined
+// transaction. Dynamic-fee transactions pay the base fee of their block plus
+// their tip, capped by their fee cap.
+func (account *account) effectiveGasPrice(ctx context.Context, tx *types.Transaction, receipt *types.Receipt) (*big.Int, error) {
+	if tx.Type() != types.DynamicFeeTxType {
+		return tx.GasPrice(), nil
+	}
+	header, err := account.client.Backend().HeaderByHash(ctx, receipt.BlockHash)
 	if err != nil {
 		return nil, err
 	}
-
-	// Keep getting current block number until it is greater than
-	// 'waitForBlocks' + transaction's block number. If context times out, the
-	// error is returned.
-	for big.NewInt(0).Sub(currentBlockNumber, blockNumber).Cmp(big.NewInt(waitForBlocks)) < 0 {
-		currentBlockNumber, err = account.client.CurrentBlockNumber(ctx)
-		if err != nil {
-			select {
-			case <-ctx.Done():
-				return nil, ctx.Err()
-			case <-time.After(time.Second):
-			}
-			continue
-		}
+	if header.BaseFee == nil {
+		return tx.GasFeeCap(), nil
 	}
-	return transction, nil
+	gasTip := tx.EffectiveGasTipValue(header.BaseFee)
+	return gasTip.Add(gasTip, header.BaseFee), nil
 }
 
 // Transfer transfers eth from the account to an ethereum address. If the value
 // is nil then it transfers all the balance to the `to` address.
 func (account *account) Transfer(ctx context.Context, to common.Address, value, gasPrice *big.Int, confirmBlocks int64, sendAll bool) (*types.Transaction, error) {
+	// The balance is read at the latest block, even if the context carries
+	// another block
+	ctx = WithBlock(ctx, LatestBlock)
+
 	// Pre-condition check: Check if the account has enough balance
 	preConditionCheck := func() bool {
 		accountBalance, err := account.client.BalanceOf(ctx, account.Address())
@@ -334,34 +664,52 @@ func (account *account) Transfer(ctx context.Context, to common.Address, value,
 
 	// Transaction: Transfer eth to address
 	f := func(transactOpts *bind.TransactOpts) (*types.Transaction, error) {
-		bound := bind.NewBoundContract(to, abi.ABI{}, nil, account.client.EthClient(), nil)
-		if gasPrice == nil {
-			gasPrice = transactOpts.GasPrice
-		}
-
-		if sendAll {
-			balance, err := account.BalanceAt(ctx, nil)
-			if err != nil {
-				return nil, err
-			}
-			value = new(big.Int).Sub(balance, new(big.Int).Mul(big.NewInt(21000), gasPrice))
-		}
+		bound := bind.NewBoundContract(to, abi.ABI{}, nil, account.client.Backend(), nil)
 
 		transactor := &bind.TransactOpts{
 			From:     transactOpts.From,
 			Signer:   transactOpts.Signer,
 			Value:    value,
 			GasLimit: 21000,
-			GasPrice: gasPrice,
 			Context:  ctx,
 		}
 		if transactOpts.Nonce != nil {
 			transactor.Nonce = big.NewInt(0).Set(transactOpts.Nonce)
 		}
-		if transactor.GasPrice == nil {
+
+		// An explicit gas price always results in a legacy transaction,
+		// otherwise the fees of the account are used
+		switch {
+		case gasPrice != nil:
+			transactor.GasPrice = big.NewInt(0).Set(gasPrice)
+		case transactOpts.GasFeeCap != nil:
+			transactor.GasFeeCap = big.NewInt(0).Set(transactOpts.GasFeeCap)
+			transactor.GasTipCap = big.NewInt(0).Set(transactOpts.GasTipCap)
+		case transactOpts.GasPrice != nil:
 			transactor.GasPrice = big.NewInt(0).Set(transactOpts.GasPrice)
 		}
 
+		if sendAll {
+			balance, err := account.BalanceAt(ctx, nil)
+			if err != nil {
+				return nil, err
+			}
+
+			// A dynamic-fee transaction can cost up to its fee cap, so the fee
+			// cap is reserved and any unspent fee remains in the account
+			maxGasPrice := transactor.GasPrice
+			if maxGasPrice == nil {
+				maxGasPrice = transactor.GasFeeCap
+			}
+			if maxGasPrice == nil {
+				if maxGasPrice, err = account.client.Backend().SuggestGasPrice(ctx); err != nil {
+					return nil, err
+				}
+				transactor.GasPrice = maxGasPrice
+			}
+			transactor.Value = new(big.Int).Sub(balance, new(big.Int).Mul(big.NewInt(21000), maxGasPrice))
+		}
+
 		tx, err := bound.Transfer(transactor)
 		if err != nil {
 			return tx, err
@@ -371,9 +719,27 @@ func (account *account) Transfer(ctx context.Context, to common.Address, value,
 	return account.Transact(ctx, preConditionCheck, f, nil, confirmBlocks)
 }
 
-// Sign the given message with the account's private key.
+// Sign the given message with the account's signer.
 func (account *account) Sign(msgHash []byte) ([]byte, error) {
-	return crypto.Sign(msgHash, account.privateKey)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	return account.signer.SignHash(ctx, msgHash)
+}
+
+// Signer returns the signer of the account.
+func (account *account) Signer() Signer {
+	return account.signer
+}
+
+// signerFn returns a function that signs the transactions of the account with
+// its signer, for use in a bind.TransactOpts.
+func (account *account) signerFn(ctx context.Context) bind.SignerFn {
+	return func(address common.Address, tx *types.Transaction) (*types.Transaction, error) {
+		if address != account.signer.Address() {
+			return nil, bind.ErrNotAuthorized
+		}
+		return account.signer.SignTx(ctx, tx, account.chainID)
+	}
 }
 
 // SetGasPrice will allow the caller to set gas price of transactOpts.
@@ -382,28 +748,113 @@ func (account *account) SetGasPrice(gasPrice float64) {
 	defer account.mu.Unlock()
 
 	account.transactOpts.GasPrice = big.NewInt(int64(gasPrice * math.Pow10(9)))
+	account.transactOpts.GasFeeCap = nil
+	account.transactOpts.GasTipCap = nil
 }
 
-// ResetToPendingNonce will allow the caller to reset nonce to pending nonce.
-// This function will wait for a 'coolDown' time (in milliseconds) before
-// updating the nonce in transactOpts.
-func (account *account) ResetToPendingNonce(ctx context.Context, coolDown time.Duration) error {
+// SetFeeOracle will allow the caller to send dynamic-fee transactions priced by
+// the given oracle, or legacy transactions if the oracle is nil.
+func (account *account) SetFeeOracle(feeOracle FeeOracle) {
+	account.mu.Lock()
+	defer account.mu.Unlock()
+
+	account.feeOracle = feeOracle
+}
+
+// SetLogger will allow the caller to receive the events of the account and its
+// client, or to discard them if the logger is nil.
+func (account *account) SetLogger(logger Logger) {
 	account.mu.Lock()
 	defer account.mu.Unlock()
 
+	if logger == nil {
+		logger = NopLogger()
+	}
+	account.logger = logger
+	account.client.SetLogger(logger)
+}
+
+// log returns the Logger of the account.
+func (account *account) log() Logger {
+	account.mu.RLock()
+	defer account.mu.RUnlock()
+
+	return account.logger
+}
+
+// SetMetrics will allow the caller to collect measurements of the account and
+// its client, or to discard them if the metrics are nil.
+func (account *account) SetMetrics(metrics *Metrics) {
+	account.mu.Lock()
+	defer account.mu.Unlock()
+
+	account.metrics = metrics
+	account.client.SetMetrics(metrics)
+}
+
+// getMetrics returns the Metrics of the account.
+func (account *account) getMetrics() *Metrics {
+	account.mu.RLock()
+	defer account.mu.RUnlock()
+
+	return account.metrics
+}
+
+// SetRevertPolicy will allow the caller to choose whether reverted
+// transactions are retried.
+func (account *account) SetRevertPolicy(policy RevertPolicy) {
+	account.mu.Lock()
+	defer account.mu.Unlock()
+
+	account.revertPolicy = policy
+}
+
+// SetReplacementPolicy will allow the caller to replace transactions that are
+// not mined in time, or to disable replacements if the policy is nil.
+func (account *account) SetReplacementPolicy(policy *ReplacementPolicy) {
+	account.mu.Lock()
+	defer account.mu.Unlock()
+
+	account.replacementPolicy = policy
+}
+
+// SetRetryPolicy will allow the caller to choose how transactions and reads
+// are retried, or to use the default policy if the policy is nil.
+func (account *account) SetRetryPolicy(policy *RetryPolicy) {
+	account.mu.Lock()
+	defer account.mu.Unlock()
+
+	account.retryPolicy = policy
+	account.client.SetRetryPolicy(policy)
+}
+
+// SetBatchSize will allow the caller to choose how many reads are sent in a
+// single JSON-RPC batch.
+func (account *account) SetBatchSize(size int) {
+	account.mu.Lock()
+	defer account.mu.Unlock()
+
+	account.client.SetBatchSize(size)
+}
+
+// ResetToPendingNonce will allow the caller to reset nonce to pending nonce.
+// This function will wait for a 'coolDown' time (in milliseconds) before
+// syncing the nonce manager of the account.
+func (account *account) ResetToPendingNonce(ctx context.Context, coolDown time.Duration) error {
 	select {
 	case <-ctx.Done():
 		return ctx.Err()
 	case <-time.After(coolDown * time.Millisecond):
 	}
 
-	// Get pending nonce
-	nonce, err := account.client.EthClient().PendingNonceAt(ctx, account.transactOpts.From)
-	if err != nil {
-		return err
-	}
-	account.transactOpts.Nonce = big.NewInt(int64(nonce))
-	return nil
+	account.log().Info("resetting nonce to pending nonce")
+	return account.nonces.Sync(ctx)
+}
+
+// ConfirmationTracker returns the tracker that Transact uses to wait for the
+// confirmations of transactions.
+func (account *account) ConfirmationTracker() ConfirmationTracker {
+	return account.confirmations
 }
 
 // FormatTransactionView returns the formatted string with the URL at which the
@@ -411,7 +862,7 @@ func (account *account) ResetToPendingNonce(ctx context.Context, coolDown time.D
 func (account *account) FormatTransactionView(msg, txHash string) (string, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
 	defer cancel()
-	netID, err := account.client.ethClient.NetworkID(ctx)
+	netID, err := account.client.Backend().NetworkID(ctx)
 	if err != nil {
 		return "", err
 	}
@@ -427,49 +878,10 @@ func (account *account) FormatTransactionView(msg, txHash string) (string, error
 	}
 }
 
-// SuggestedGasPrice returns the gas price that ethGasStation recommends for
-// transactions to be mined on Ethereum blockchain based on the speed provided.
-func SuggestedGasPrice(txSpeed TxExecutionSpeed) (*big.Int, error) {
-	request, err := http.NewRequest("GET", "https://ethgasstation.info/json/ethgasAPI.json", nil)
-	if err != nil {
-		return nil, fmt.Errorf("cannot build request to ethGasStation = %v", err)
-	}
-	request.Header.Set("Content-Type", "application/json")
-
-	res, err := (&http.Client{}).Do(request)
-	if err != nil {
-		return nil, fmt.Errorf("cannot connect to ethGasStationAPI = %v", err)
-	}
-	if res.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("unexpected status code %v from ethGasStation", res.StatusCode)
-	}
-
-	data := struct {
-		SafeLow float64 `json:"safeLow"`
-		Average float64 `json:"average"`
-		Fast    float64 `json:"fast"`
-		Fastest float64 `json:"fastest"`
-	}{}
-	if err = json.NewDecoder(res.Body).Decode(&data); err != nil {
-		return nil, fmt.Errorf("cannot decode response body from ethGasStation = %v", err)
-	}
-
-	switch txSpeed {
-	case SafeLow:
-		return big.NewInt(int64(data.SafeLow * math.Pow10(8))), nil
-	case Average:
-		return big.NewInt(int64(data.Average * math.Pow10(8))), nil
-	case Fast:
-		return big.NewInt(int64(data.Fast * math.Pow10(8))), nil
-	case Fastest:
-		return big.NewInt(int64(data.Fastest * math.Pow10(8))), nil
-	default:
-		return nil, fmt.Errorf("invalid speed tier: %v", txSpeed)
-	}
-}
-
 // retryNonceTx retries transaction execution on the blockchain until nonce
-// errors are not seen, or until the context times out.
+// errors are not seen, or until the context times out. Every attempt uses a
+// nonce reserved from the nonce manager of the account, which stays pending
+// until the transaction is mined or dropped.
 func (account *account) retryNonceTx(ctx context.Context, f func(*bind.TransactOpts) (*types.Transaction, error)) (*types.Transaction, error) {
 
 	select {
@@ -478,78 +890,174 @@ func (account *account) retryNonceTx(ctx context.Context, f func(*bind.TransactO
 	default:
 	}
 
-	transactor := &bind.TransactOpts{
-		From:     account.transactOpts.From,
-		Signer:   account.transactOpts.Signer,
-		Value:    big.NewInt(0),
-		GasLimit: account.transactOpts.GasLimit,
-		Context:  ctx,
-	}
-	if account.transactOpts.Nonce != nil {
-		transactor.Nonce = big.NewInt(0).Set(account.transactOpts.Nonce)
-	}
-	if account.transactOpts.GasPrice != nil {
-		transactor.GasPrice = big.NewInt(0).Set(account.transactOpts.GasPrice)
-	}
+	nonce := account.nonces.Next()
+	tx, err := account.send(ctx, f, nonce)
 
-	tx, err := f(transactor)
-
-	// On successful execution, increment nonce in transactOpts and return
+	// On successful execution, the nonce remains pending until the
+	// transaction is mined
 	if err == nil {
-		account.transactOpts.Nonce.Add(account.transactOpts.Nonce, big.NewInt(1))
 		return tx, nil
 	}
 
 	// Process errors to check for nonce issues
-	// If error indicates that nonce is too low, increment nonce and retry
-	if err == core.ErrNonceTooLow || strings.Contains(err.Error(), "nonce is too low") {
-		account.transactOpts.Nonce.Add(account.transactOpts.Nonce, big.NewInt(1))
+	// If error indicates that nonce is too low, the nonce has already been
+	// used so mark it as done and retry with the next nonce
+	if errors.Is(err, ErrNonceTooLow) {
+		account.log().Info("nonce too low, retrying with next nonce", "nonce", nonce, "err", err)
+		account.getMetrics().nonceCorrection("too_low")
+		account.nonces.Done(nonce)
 		return account.retryNonceTx(ctx, f)
 	}
 
-	// If error indicates that nonce is too low, decrement nonce and retry
-	if err == core.ErrNonceTooHigh || strings.Contains(err.Error(), "nonce is too high") {
-		account.transactOpts.Nonce.Sub(account.transactOpts.Nonce, big.NewInt(1))
+	// If error indicates that nonce is too high, there is a gap in the nonce
+	// sequence so release the nonce, sync with the pending nonce and retry
+	if errors.Is(err, ErrNonceTooHigh) {
+		account.log().Info("nonce too high, syncing nonce", "nonce", nonce, "err", err)
+		account.getMetrics().nonceCorrection("too_high")
+		account.nonces.Release(nonce)
+		if err := account.nonces.Sync(ctx); err != nil {
+			return nil, err
+		}
 		return account.retryNonceTx(ctx, f)
 	}
 
 	// If any other type of nonce error occurs we will refresh the nonce and
 	// try again for up to 1 minute
-	var nonce uint64
-	for try := 0; try < 60 && strings.Contains(err.Error(), "nonce"); try++ {
+	for try := 0; try < 60 && isNonceError(err); try++ {
+		account.log().Info("invalid nonce, syncing nonce", "nonce", nonce, "try", try+1, "err", err)
+		account.getMetrics().nonceCorrection("invalid")
+		account.nonces.Release(nonce)
+
 		select {
 		case <-ctx.Done():
 			return nil, ctx.Err()
 		case <-time.After(time.Second):
 		}
 
-		// Get updated nonce and retry 'f'
-		nonce, err = account.client.EthClient().PendingNonceAt(ctx, account.transactOpts.From)
-		if err != nil {
+		// Sync the nonce manager and retry 'f'
+		syncErr := account.nonces.Sync(ctx)
+		nonce = account.nonces.Next()
+		if syncErr != nil {
+			err = syncErr
 			continue
 		}
-		account.transactOpts.Nonce = big.NewInt(int64(nonce))
-
-		if tx, err = f(account.transactOpts); err == nil {
-			account.transactOpts.Nonce.Add(account.transactOpts.Nonce, big.NewInt(1))
+		if tx, err = account.send(ctx, f, nonce); err == nil {
 			return tx, nil
 		}
 	}
 
+	// The transaction was not sent, so its nonce can be re-used
+	account.nonces.Release(nonce)
 	return tx, err
 }
 
-// updateGasPrice will retrieve the current 'fast' gas price
-// and update the account's transactOpts. This function expects the caller to
-// handle potential data race conditions (i.e. Locking of mutex prior to
-// calling this method)
-func (account *account) updateGasPrice(txSpeed TxExecutionSpeed) error {
-	gasPrice, err := SuggestedGasPrice(txSpeed)
+// send executes 'f' with a transactor that uses the given nonce, and returns
+// the classified error. If the node already knows the transaction, it has been
+// sent before, so the transaction that was signed is returned as if it was
+// sent and is waited for like any other transaction.
+func (account *account) send(ctx context.Context, f func(*bind.TransactOpts) (*types.Transaction, error), nonce uint64) (*types.Transaction, error) {
+	transactor := account.transactor(ctx, nonce)
+	signerFn := transactor.Signer
+	var signed *types.Transaction
+	transactor.Signer = func(address common.Address, tx *types.Transaction) (*types.Transaction, error) {
+		signedTx, err := signerFn(address, tx)
+		if err == nil {
+			signed = signedTx
+		}
+		return signedTx, err
+	}
+
+	tx, err := f(transactor)
+	err = ClassifyError(err)
+	if errors.Is(err, ErrAlreadyKnown) && signed != nil {
+		account.log().Info("transaction already known", "hash", signed.Hash(), "nonce", signed.Nonce())
+		return signed, nil
+	}
+	return tx, err
+}
+
+// dropped returns true if the node knows none of the transactions. A
+// transaction that cannot be looked up is assumed to be known. The context of
+// the transactions is usually done by the time they are checked, so the node
+// is asked using a context of its own.
+func (account *account) dropped(txs []*types.Transaction) bool {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	for _, tx := range txs {
+		if _, _, err := account.client.Backend().TransactionByHash(ctx, tx.Hash()); err != ethereum.NotFound {
+			return false
+		}
+	}
+	return true
+}
+
+// transactor returns a copy of the account's transactOpts that uses the given
+// nonce.
+func (account *account) transactor(ctx context.Context, nonce uint64) *bind.TransactOpts {
+	account.mu.RLock()
+	defer account.mu.RUnlock()
+
+	transactor := &bind.TransactOpts{
+		From:     account.transactOpts.From,
+		Signer:   account.signerFn(ctx),
+		Nonce:    big.NewInt(0).SetUint64(nonce),
+		Value:    big.NewInt(0),
+		GasLimit: account.transactOpts.GasLimit,
+		Context:  ctx,
+	}
+	if account.transactOpts.GasPrice != nil {
+		transactor.GasPrice = big.NewInt(0).Set(account.transactOpts.GasPrice)
+	}
+	if account.transactOpts.GasFeeCap != nil {
+		transactor.GasFeeCap = big.NewInt(0).Set(account.transactOpts.GasFeeCap)
+	}
+	if account.transactOpts.GasTipCap != nil {
+		transactor.GasTipCap = big.NewInt(0).Set(account.transactOpts.GasTipCap)
+	}
+	return transactor
+}
+
+// updateGasPrice will retrieve the current gas price for the given speed tier
+// from the account's gas price oracle and update the account's transactOpts.
+// If the account has a fee oracle, the EIP-1559 fees are updated instead. The
+// oracles are queried without holding the lock of the account, so that a slow
+// oracle does not block other transactions, and the caller must not hold it.
+func (account *account) updateGasPrice(ctx context.Context, txSpeed TxExecutionSpeed) error {
+	account.mu.RLock()
+	gasPriceOracle := account.gasPriceOracle
+	feeOracle := account.feeOracle
+	account.mu.RUnlock()
+
+	if feeOracle != nil {
+		gasFeeCap, gasTipCap, err := feeOracle.SuggestFees(ctx, txSpeed)
+		if err != nil {
+			account.log().Warn("cannot update fees", "err", err)
+			return err
+		}
+		account.mu.Lock()
+		account.transactOpts.GasPrice = nil
+		account.transactOpts.GasFeeCap = gasFeeCap
+		account.transactOpts.GasTipCap = gasTipCap
+		account.mu.Unlock()
+		account.log().Debug("fees updated", "gasFeeCap", gasFeeCap, "gasTipCap", gasTipCap)
+		account.getMetrics().setGasPrice(account.Address(), gasFeeCap)
+		return nil
+	}
+
+	gasPrice, err := gasPriceOracle.SuggestGasPrice(ctx, txSpeed)
 	if err != nil {
+		account.log().Warn("cannot update gas price", "err", err)
 		return err
 	}
 	if gasPrice != nil {
+		account.mu.Lock()
 		account.transactOpts.GasPrice = gasPrice
+		account.transactOpts.GasFeeCap = nil
+		account.transactOpts.GasTipCap = nil
+		account.mu.Unlock()
+		account.log().Debug("gas price updated", "gasPrice", gasPrice)
+		account.getMetrics().setGasPrice(account.Address(), gasPrice)
 	}
 	return nil
 }
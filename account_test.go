@@ -0,0 +1,350 @@
+package beth_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/republicprotocol/beth-go"
+)
+
+var _ = Describe("accounts", func() {
+
+	Context("when the node already knows the transaction", func() {
+		var chain *fakeChain
+		var server *httptest.Server
+
+		BeforeEach(func() {
+			chain = newFakeChain(100)
+			server = httptest.NewServer(chain)
+		})
+
+		AfterEach(func() {
+			server.Close()
+		})
+
+		It("should wait for the known transaction instead of sending another one", func() {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+			defer cancel()
+
+			key, err := crypto.GenerateKey()
+			Expect(err).ShouldNot(HaveOccurred())
+			account, err := beth.NewAccount(server.URL, key, beth.NewFixedGasPriceOracle(big.NewInt(10e9)))
+			Expect(err).ShouldNot(HaveOccurred())
+
+			// The transaction is mined, but sending it returns an error as if
+			// it had been sent before
+			chain.mu.Lock()
+			chain.sendErr = "already known"
+			chain.mu.Unlock()
+			calls := 0
+			var signed *types.Transaction
+			f := func(txOpts *bind.TransactOpts) (*types.Transaction, error) {
+				calls++
+				tx := types.NewTransaction(txOpts.Nonce.Uint64(), common.Address{}, big.NewInt(0), 21000, txOpts.GasPrice, nil)
+				if signed, err = txOpts.Signer(txOpts.From, tx); err != nil {
+					return nil, err
+				}
+				chain.mu.Lock()
+				chain.mine(signed, chain.head)
+				chain.mu.Unlock()
+				return nil, account.Backend().SendTransaction(txOpts.Context, signed)
+			}
+
+			result, err := account.TransactWithResult(ctx, nil, f, nil, 0)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(calls).Should(Equal(1))
+			Expect(result.Transaction.Hash()).Should(Equal(signed.Hash()))
+			Expect(result.Attempts).Should(Equal([]beth.TransactAttempt{{Hash: signed.Hash()}}))
+		})
+	})
+
+	Context("when the post-condition passes without a mined transaction", func() {
+		var chain *fakeChain
+		var server *httptest.Server
+
+		BeforeEach(func() {
+			chain = newFakeChain(100)
+			server = httptest.NewServer(chain)
+		})
+
+		AfterEach(func() {
+			server.Close()
+		})
+
+		It("should return a result with the attempts, but no transaction", func() {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+			defer cancel()
+
+			key, err := crypto.GenerateKey()
+			Expect(err).ShouldNot(HaveOccurred())
+			account, err := beth.NewAccount(server.URL, key, beth.NewFixedGasPriceOracle(big.NewInt(10e9)))
+			Expect(err).ShouldNot(HaveOccurred())
+			sendErr := errors.New("cannot send transaction")
+			f := func(txOpts *bind.TransactOpts) (*types.Transaction, error) {
+				return nil, sendErr
+			}
+
+			result, err := account.TransactWithResult(ctx, nil, f, func() bool { return true }, 0)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(result.Transaction).Should(BeNil())
+			Expect(result.Receipt).Should(BeNil())
+			Expect(result.Replacements).Should(BeEmpty())
+			Expect(result.Attempts).Should(Equal([]beth.TransactAttempt{{Err: sendErr}}))
+
+			tx, err := account.Transact(ctx, nil, f, func() bool { return true }, 0)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(tx).Should(BeNil())
+		})
+	})
+
+	Context("when there is no post-condition and every attempt fails", func() {
+		var chain *fakeChain
+		var server *httptest.Server
+
+		BeforeEach(func() {
+			chain = newFakeChain(100)
+			server = httptest.NewServer(chain)
+		})
+
+		AfterEach(func() {
+			server.Close()
+		})
+
+		It("should return the error of the last attempt", func() {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+			defer cancel()
+			ctx = beth.WithRetryPolicy(ctx, beth.RetryPolicy{InitialDelay: time.Millisecond, MaxAttempts: 2})
+
+			key, err := crypto.GenerateKey()
+			Expect(err).ShouldNot(HaveOccurred())
+			account, err := beth.NewAccount(server.URL, key, beth.NewFixedGasPriceOracle(big.NewInt(10e9)))
+			Expect(err).ShouldNot(HaveOccurred())
+			calls := 0
+			f := func(txOpts *bind.TransactOpts) (*types.Transaction, error) {
+				calls++
+				return nil, errors.New("txpool is full")
+			}
+
+			tx, err := account.Transact(ctx, nil, f, nil, 0)
+			Expect(err).Should(MatchError(beth.ErrTxPoolFull))
+			Expect(tx).Should(BeNil())
+			Expect(calls).Should(Equal(2))
+		})
+
+		It("should return the attempts along with the error", func() {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+			defer cancel()
+			ctx = beth.WithRetryPolicy(ctx, beth.RetryPolicy{InitialDelay: time.Millisecond, MaxAttempts: 2})
+
+			key, err := crypto.GenerateKey()
+			Expect(err).ShouldNot(HaveOccurred())
+			account, err := beth.NewAccount(server.URL, key, beth.NewFixedGasPriceOracle(big.NewInt(10e9)))
+			Expect(err).ShouldNot(HaveOccurred())
+			f := func(txOpts *bind.TransactOpts) (*types.Transaction, error) {
+				return nil, errors.New("insufficient funds for gas * price + value")
+			}
+
+			result, err := account.TransactWithResult(ctx, nil, f, nil, 0)
+			Expect(err).Should(MatchError(beth.ErrInsufficientFunds))
+			Expect(result).ShouldNot(BeNil())
+			Expect(result.Transaction).Should(BeNil())
+			Expect(result.Attempts).Should(HaveLen(1))
+			Expect(result.Attempts[0].Err).Should(MatchError("insufficient funds for gas * price + value"))
+		})
+	})
+
+	Context("when a transaction runs out of gas", func() {
+		var chain *fakeChain
+		var server *httptest.Server
+
+		BeforeEach(func() {
+			chain = newFakeChain(100)
+			server = httptest.NewServer(chain)
+		})
+
+		AfterEach(func() {
+			server.Close()
+		})
+
+		It("should send the transaction again without waiting for the post-condition", func() {
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+			ctx = beth.WithRetryPolicy(ctx, beth.RetryPolicy{InitialDelay: time.Millisecond})
+
+			key, err := crypto.GenerateKey()
+			Expect(err).ShouldNot(HaveOccurred())
+			account, err := beth.NewAccount(server.URL, key, beth.NewFixedGasPriceOracle(big.NewInt(10e9)))
+			Expect(err).ShouldNot(HaveOccurred())
+
+			// The first transaction uses all of its gas, and fails
+			calls := 0
+			succeeded := false
+			f := func(txOpts *bind.TransactOpts) (*types.Transaction, error) {
+				calls++
+				tx := types.NewTransaction(txOpts.Nonce.Uint64(), common.Address{}, big.NewInt(0), 21000, txOpts.GasPrice, nil)
+				signed, err := txOpts.Signer(txOpts.From, tx)
+				if err != nil {
+					return nil, err
+				}
+				chain.mu.Lock()
+				chain.mine(signed, chain.head)
+				if calls == 1 {
+					chain.receipts[signed.Hash()].Status = types.ReceiptStatusFailed
+				} else {
+					succeeded = true
+				}
+				chain.mu.Unlock()
+				return signed, account.Backend().SendTransaction(txOpts.Context, signed)
+			}
+			postCondition := func() bool {
+				chain.mu.Lock()
+				defer chain.mu.Unlock()
+				return succeeded
+			}
+
+			result, err := account.TransactWithResult(ctx, nil, f, postCondition, 0)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(calls).Should(Equal(2))
+			Expect(result.Attempts).Should(HaveLen(2))
+			Expect(result.Attempts[0].Err).Should(Equal(beth.ErrOutOfGas))
+			Expect(result.Status).Should(Equal(types.ReceiptStatusSuccessful))
+		})
+	})
+
+	Context("when a transaction reverts", func() {
+		var chain *fakeChain
+		var server *httptest.Server
+		var token common.Address
+
+		BeforeEach(func() {
+			chain = newFakeChain(100)
+			token = common.HexToAddress("0x0000000000000000000000000000000000000ca1")
+			chain.contracts[token] = func(*fakeChain, []byte) ([]byte, bool) {
+				return revertData("insufficient balance"), false
+			}
+		})
+
+		AfterEach(func() {
+			server.Close()
+		})
+
+		// transact sends a transaction to the token, which reverts when it
+		// is mined.
+		transact := func(ctx context.Context) error {
+			key, err := crypto.GenerateKey()
+			Expect(err).ShouldNot(HaveOccurred())
+			account, err := beth.NewAccount(server.URL, key, beth.NewFixedGasPriceOracle(big.NewInt(10e9)))
+			Expect(err).ShouldNot(HaveOccurred())
+			f := func(txOpts *bind.TransactOpts) (*types.Transaction, error) {
+				tx := types.NewTransaction(txOpts.Nonce.Uint64(), token, big.NewInt(0), 100000, txOpts.GasPrice, nil)
+				signed, err := txOpts.Signer(txOpts.From, tx)
+				if err != nil {
+					return nil, err
+				}
+				chain.mu.Lock()
+				chain.mine(signed, chain.head)
+				chain.receipts[signed.Hash()].Status = types.ReceiptStatusFailed
+				chain.mu.Unlock()
+				return signed, account.Backend().SendTransaction(txOpts.Context, signed)
+			}
+			_, err = account.TransactWithResult(ctx, nil, f, nil, 0)
+			return err
+		}
+
+		It("should replay the transaction on top of the parent block to recover the reason", func() {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+			defer cancel()
+			server = httptest.NewServer(chain)
+
+			err := transact(ctx)
+			var reverted *beth.ErrReverted
+			Expect(errors.As(err, &reverted)).Should(BeTrue())
+			Expect(reverted.Reason).Should(Equal("insufficient balance"))
+			Expect(reverted.ReplayErr).ShouldNot(HaveOccurred())
+
+			chain.mu.Lock()
+			defer chain.mu.Unlock()
+			Expect(chain.callBlocks).Should(Equal([]string{hexutil.EncodeUint64(chain.head - 1)}))
+		})
+
+		It("should return the error of the replay separately if it cannot be replayed", func() {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+			defer cancel()
+			server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, err := ioutil.ReadAll(r.Body)
+				Expect(err).ShouldNot(HaveOccurred())
+				if bytes.Contains(body, []byte(`"eth_call"`)) {
+					http.Error(w, "service unavailable", http.StatusServiceUnavailable)
+					return
+				}
+				r.Body = ioutil.NopCloser(bytes.NewReader(body))
+				chain.ServeHTTP(w, r)
+			}))
+
+			err := transact(ctx)
+			var reverted *beth.ErrReverted
+			Expect(errors.As(err, &reverted)).Should(BeTrue())
+			Expect(reverted.Reason).Should(BeEmpty())
+			Expect(reverted.ReplayErr).Should(HaveOccurred())
+			Expect(reverted.ReplayErr.Error()).Should(ContainSubstring("service unavailable"))
+		})
+	})
+
+	Context("when the transaction is mined", func() {
+		var chain *simulatedChain
+
+		BeforeEach(func() {
+			chain = newSimulatedChain(1)
+		})
+
+		AfterEach(func() {
+			chain.close()
+		})
+
+		It("should describe the transaction and its receipt", func() {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+			defer cancel()
+
+			account := chain.newAccount(0)
+			to := common.HexToAddress("0x000000000000000000000000000000000000dEaD")
+			f := func(txOpts *bind.TransactOpts) (*types.Transaction, error) {
+				bound := bind.NewBoundContract(to, abi.ABI{}, nil, account.Backend(), nil)
+				txOpts.Value = big.NewInt(1)
+				txOpts.GasLimit = 21000
+				return bound.Transfer(txOpts)
+			}
+
+			result, err := account.TransactWithResult(ctx, nil, f, nil, 2)
+			Expect(err).ShouldNot(HaveOccurred())
+			tx := result.Transaction
+			Expect(tx).ShouldNot(BeNil())
+			Expect(result.Attempts).Should(Equal([]beth.TransactAttempt{{Hash: tx.Hash()}}))
+			Expect(result.Replacements).Should(BeEmpty())
+
+			receipt, err := account.Backend().TransactionReceipt(ctx, tx.Hash())
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(result.Receipt.TxHash).Should(Equal(tx.Hash()))
+			Expect(result.Status).Should(Equal(types.ReceiptStatusSuccessful))
+			Expect(result.GasUsed).Should(Equal(uint64(21000)))
+			Expect(result.BlockNumber).Should(Equal(receipt.BlockNumber))
+			Expect(result.BlockHash).Should(Equal(receipt.BlockHash))
+			Expect(result.EffectiveGasPrice).Should(Equal(big.NewInt(10e9)))
+			Expect(result.Confirmations).Should(BeNumerically(">=", 2))
+		})
+	})
+})
@@ -0,0 +1,169 @@
+package beth
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"net/http"
+	"time"
+
+	ethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// ErrBlockNotSupported is returned when state is read from a Backend at a
+// block that it cannot refer to, such as the SafeBlock.
+var ErrBlockNotSupported = errors.New("block is not supported by the backend")
+
+// ErrBlockNotCanonical is returned when state is read from a Backend at a block
+// hash that is not in the canonical chain.
+var ErrBlockNotCanonical = errors.New("block is not in the canonical chain")
+
+// Backend is the connection to an Ethereum node that a Client reads from, and
+// that an Account sends its transactions to. It is implemented by
+// *ethclient.Client, and can be implemented by fakes, simulated backends, or
+// wrappers around several nodes. The safe and finalized blocks are only read
+// from backends that also have a `Client() *rpc.Client` method, and the
+// pending block from backends that have PendingBalanceAt and
+// PendingCallContract methods.
+type Backend interface {
+	bind.ContractBackend
+	bind.DeployBackend
+	ethereum.ChainReader
+	ethereum.ChainStateReader
+	ethereum.TransactionReader
+
+	// ChainID returns the chain ID used to sign transactions.
+	ChainID(ctx context.Context) (*big.Int, error)
+
+	// NetworkID returns the network ID of the node.
+	NetworkID(ctx context.Context) (*big.Int, error)
+
+	// BlockNumber returns the number of the latest block.
+	BlockNumber(ctx context.Context) (uint64, error)
+
+	// FeeHistory returns the base fees and priority fees of recent blocks.
+	FeeHistory(ctx context.Context, blockCount uint64, lastBlock *big.Int, rewardPercentiles []float64) (*ethereum.FeeHistory, error)
+}
+
+// NewClient returns a Client that reads from the backend. The client has no
+// JSON-RPC endpoint, so raw JSON-RPC requests and batched reads return
+// ErrNoEndpoints, but all other reads are made through the backend. Closing
+// the client closes the backend if it has a Close method.
+func NewClient(backend Backend) (Client, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	netID, err := backend.NetworkID(ctx)
+	if err != nil {
+		return Client{}, err
+	}
+
+	return Client{
+		backend:  backend,
+		addrBook: DefaultAddressBook(netID.Int64()),
+		headers:  http.Header{},
+		logger:   NopLogger(),
+	}, nil
+}
+
+// Backend returns the backend that the client reads from.
+func (client *Client) Backend() Backend {
+	return client.backend
+}
+
+// hasEndpoint returns true if the client has a JSON-RPC endpoint, or false if
+// it only has a backend.
+func (client *Client) hasEndpoint() bool {
+	return client.url != ""
+}
+
+// backendBlock returns the number of the block that the backend reads state
+// at, which is nil for the LatestBlock. The PendingBlock has no number that
+// every backend accepts, so it is read using the pending methods of the
+// backend instead.
+func (client *Client) backendBlock(ctx context.Context, block BlockRef) (*big.Int, error) {
+	switch {
+	case block.number != nil:
+		return block.number, nil
+	case block.hash != nil:
+		header, err := client.backend.HeaderByHash(ctx, *block.hash)
+		if err != nil {
+			return nil, err
+		}
+
+		// State is read at the number of the block, so the block must be the
+		// canonical block at that number
+		canonical, err := client.backend.HeaderByNumber(ctx, header.Number)
+		if err != nil {
+			return nil, err
+		}
+		if canonical.Hash() != header.Hash() {
+			return nil, ErrBlockNotCanonical
+		}
+		return header.Number, nil
+	case block.tag == "" || block.tag == LatestBlock.tag:
+		return nil, nil
+	}
+	return nil, ErrBlockNotSupported
+}
+
+// pendingBalanceReader is implemented by backends, such as *ethclient.Client,
+// that can read balances at the pending block.
+type pendingBalanceReader interface {
+	PendingBalanceAt(ctx context.Context, account common.Address) (*big.Int, error)
+}
+
+// backendBlockNumber returns the number of the block with the given tag, read
+// from the backend. The numbers that go-ethereum uses for the safe and
+// finalized blocks cannot be sent as block numbers, so these blocks are read by
+// their tag using the rpc.Client of the backend. ErrBlockNotSupported is
+// returned if the backend has no `Client() *rpc.Client` method.
+func (client *Client) backendBlockNumber(ctx context.Context, tag string) (*big.Int, error) {
+	var header *types.Header
+	switch tag {
+	case LatestBlock.tag:
+		err := client.Get(ctx, func() (err error) {
+			header, err = client.backend.HeaderByNumber(ctx, nil)
+			return
+		})
+		if err != nil {
+			return nil, err
+		}
+	case SafeBlock.tag, FinalizedBlock.tag:
+		rpcBackend, ok := client.backend.(interface{ Client() *rpc.Client })
+		if !ok {
+			return nil, ErrBlockNotSupported
+		}
+		err := client.roundTrip(ctx, func() error {
+			return rpcBackend.Client().CallContext(ctx, &header, "eth_getBlockByNumber", tag, false)
+		})
+		if err != nil {
+			return nil, rpcErr(err)
+		}
+		if header == nil {
+			return nil, ethereum.NotFound
+		}
+	default:
+		return nil, ErrBlockNotSupported
+	}
+	return header.Number, nil
+}
+
+// backendTxBlockNumber returns the number of the block that the transaction
+// was mined in, read from the backend. It waits until the transaction is
+// mined, or until the retry policy stops retrying.
+func (client *Client) backendTxBlockNumber(ctx context.Context, hash common.Hash) (*big.Int, error) {
+	var receipt *types.Receipt
+	err := client.Get(ctx, func() (err error) {
+		receipt, err = client.backend.TransactionReceipt(ctx, hash)
+		return
+	})
+	if err != nil {
+		return nil, err
+	}
+	return receipt.BlockNumber, nil
+}
@@ -0,0 +1,226 @@
+package beth_test
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"net/http/httptest"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	ethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind/backends"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/republicprotocol/beth-go"
+)
+
+// simulatedBackend implements the methods of a beth.Backend that the simulated
+// backend of go-ethereum does not have.
+type simulatedBackend struct {
+	*backends.SimulatedBackend
+}
+
+func (backend simulatedBackend) ChainID(ctx context.Context) (*big.Int, error) {
+	return backend.Blockchain().Config().ChainID, nil
+}
+
+func (backend simulatedBackend) NetworkID(ctx context.Context) (*big.Int, error) {
+	return backend.Blockchain().Config().ChainID, nil
+}
+
+func (backend simulatedBackend) BlockNumber(ctx context.Context) (uint64, error) {
+	return backend.Blockchain().CurrentBlock().NumberU64(), nil
+}
+
+func (backend simulatedBackend) FeeHistory(ctx context.Context, blockCount uint64, lastBlock *big.Int, rewardPercentiles []float64) (*ethereum.FeeHistory, error) {
+	return nil, errors.New("fee history is not supported")
+}
+
+// ethClient is embedded in rpcBackend under a name that does not collide with
+// its Client method.
+type ethClient = ethclient.Client
+
+// rpcBackend is an ethclient backend that exposes its rpc.Client.
+type rpcBackend struct {
+	*ethClient
+	rpcClient *rpc.Client
+}
+
+func (backend rpcBackend) Client() *rpc.Client {
+	return backend.rpcClient
+}
+
+var _ = Describe("backends", func() {
+
+	var chain *simulatedChain
+
+	BeforeEach(func() {
+		chain = newSimulatedChain(2)
+	})
+
+	AfterEach(func() {
+		chain.close()
+	})
+
+	newAccount := func(i int) beth.Account {
+		account, err := beth.NewAccountWithBackend(simulatedBackend{chain.backend}, chain.keys[i], beth.NewFixedGasPriceOracle(big.NewInt(10e9)))
+		Expect(err).ShouldNot(HaveOccurred())
+		return account
+	}
+
+	Context("when an account is created with a backend", func() {
+		It("should send transactions through the backend", func() {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+			defer cancel()
+
+			from, to := newAccount(0), newAccount(1)
+			Expect(from.EthClient()).Should(BeNil())
+			Expect(from.Backend()).Should(Equal(simulatedBackend{chain.backend}))
+
+			value := big.NewInt(1e18)
+			tx, err := from.Transfer(ctx, to.Address(), value, nil, 1, false)
+			Expect(err).ShouldNot(HaveOccurred())
+
+			balance, err := to.BalanceAt(ctx, nil)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(balance).Should(Equal(new(big.Int).Add(simulatedChainFunds, value)))
+
+			client := from.Client()
+			txBlock, err := client.TxBlockNumber(ctx, tx.Hash().Hex())
+			Expect(err).ShouldNot(HaveOccurred())
+			currentBlock, err := client.CurrentBlockNumber(ctx)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(currentBlock.Cmp(txBlock)).Should(BeNumerically(">=", 0))
+		})
+
+		It("should read tokens through the backend", func() {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+			defer cancel()
+
+			account := newAccount(0)
+			token, err := account.NewERC20(chain.erc20.Hex())
+			Expect(err).ShouldNot(HaveOccurred())
+			balance, err := token.BalanceOf(ctx, account.Address())
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(balance).Should(Equal(simulatedChainFunds))
+		})
+
+		It("should return ErrNoEndpoints for JSON-RPC requests", func() {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+			defer cancel()
+
+			client := newAccount(0).Client()
+			var blockNumber string
+			Expect(client.Call(ctx, &blockNumber, "eth_blockNumber")).Should(Equal(beth.ErrNoEndpoints))
+			_, err := client.BalancesOf(ctx, []common.Address{{}})
+			Expect(err).Should(Equal(beth.ErrNoEndpoints))
+		})
+
+		It("should return ErrBlockNotSupported for blocks that the backend cannot read", func() {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+			defer cancel()
+
+			client := newAccount(0).Client()
+			_, err := client.BalanceOf(beth.WithBlock(ctx, beth.SafeBlock), common.Address{})
+			Expect(err).Should(Equal(beth.ErrBlockNotSupported))
+			_, err = client.SafeBlockNumber(ctx)
+			Expect(err).Should(Equal(beth.ErrBlockNotSupported))
+		})
+	})
+
+	Context("when a client is created with a JSON-RPC backend", func() {
+		var fake *fakeChain
+		var server *httptest.Server
+		var client beth.Client
+
+		BeforeEach(func() {
+			fake = newFakeChain(100)
+			server = httptest.NewServer(fake)
+
+			rpcClient, err := rpc.Dial(server.URL)
+			Expect(err).ShouldNot(HaveOccurred())
+			client, err = beth.NewClient(rpcBackend{ethclient.NewClient(rpcClient), rpcClient})
+			Expect(err).ShouldNot(HaveOccurred())
+		})
+
+		AfterEach(func() {
+			server.Close()
+		})
+
+		It("should read the safe and finalized blocks by their tags", func() {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+			defer cancel()
+
+			fake.mu.Lock()
+			fake.finalizedLag = 3
+			head := fake.head
+			fake.mu.Unlock()
+
+			safe, err := client.SafeBlockNumber(ctx)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(safe.Uint64()).Should(Equal(head - 3))
+			finalized, err := client.FinalizedBlockNumber(ctx)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(finalized.Uint64()).Should(Equal(head - 3))
+		})
+
+		It("should return an error if the chain has no finalized block", func() {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+			defer cancel()
+
+			_, err := client.FinalizedBlockNumber(ctx)
+			Expect(err).Should(HaveOccurred())
+			Expect(err.Error()).Should(ContainSubstring("finalized block not found"))
+		})
+
+		It("should read state at the pending block", func() {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+			defer cancel()
+
+			key, err := crypto.GenerateKey()
+			Expect(err).ShouldNot(HaveOccurred())
+			account, err := beth.NewAccountWithBackend(client.Backend(), key, beth.NewFixedGasPriceOracle(big.NewInt(10e9)))
+			Expect(err).ShouldNot(HaveOccurred())
+			token, err := account.NewERC20(common.Address{1}.Hex())
+			Expect(err).ShouldNot(HaveOccurred())
+
+			fake.mu.Lock()
+			fake.balance = big.NewInt(7)
+			fake.mu.Unlock()
+			pendingCtx := beth.WithBlock(ctx, beth.PendingBlock)
+			balance, err := client.BalanceOf(pendingCtx, common.Address{})
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(balance).Should(Equal(big.NewInt(7)))
+			balance, err = token.BalanceOf(pendingCtx, common.Address{})
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(balance).Should(Equal(big.NewInt(7)))
+
+			fake.mu.Lock()
+			defer fake.mu.Unlock()
+			Expect(fake.balanceBlocks).Should(Equal([]string{"pending"}))
+			Expect(fake.callBlocks).Should(Equal([]string{"pending"}))
+		})
+
+		It("should only read state at block hashes in the canonical chain", func() {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+			defer cancel()
+
+			fake.mu.Lock()
+			canonical := fake.header(90).Hash()
+			stale := fake.header(90)
+			stale.Extra = []byte("b")
+			fake.stale[stale.Hash()] = stale
+			fake.mu.Unlock()
+
+			_, err := client.BalanceOf(beth.WithBlock(ctx, beth.BlockAtHash(canonical)), common.Address{})
+			Expect(err).ShouldNot(HaveOccurred())
+			_, err = client.BalanceOf(beth.WithBlock(ctx, beth.BlockAtHash(stale.Hash())), common.Address{})
+			Expect(err).Should(Equal(beth.ErrBlockNotCanonical))
+		})
+	})
+})
@@ -0,0 +1,157 @@
+package beth
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// DefaultBatchSize is the number of reads sent in a single JSON-RPC batch when
+// the client has no batch size.
+const DefaultBatchSize = 100
+
+// ErrBatchFailed is returned by batched reads when some of the reads fail. The
+// results of the other reads are still returned.
+type ErrBatchFailed struct {
+
+	// Errs maps the index of every read that failed to its error.
+	Errs map[int]error
+
+	// Total number of reads in the batch.
+	Total int
+}
+
+// Error implements the error interface.
+func (err *ErrBatchFailed) Error() string {
+	indices := make([]int, 0, len(err.Errs))
+	for i := range err.Errs {
+		indices = append(indices, i)
+	}
+	sort.Ints(indices)
+	if len(indices) == 0 {
+		return fmt.Sprintf("0 of %v reads failed", err.Total)
+	}
+	return fmt.Sprintf("%v of %v reads failed: read %v: %v", len(indices), err.Total, indices[0], err.Errs[indices[0]])
+}
+
+// SetBatchSize sets the number of reads that batched reads, such as
+// BalancesOf, send in a single JSON-RPC batch. Setting a size that is not
+// positive uses the DefaultBatchSize.
+func (client *Client) SetBatchSize(size int) {
+	client.batchSize = size
+}
+
+// BalancesOf returns the ethereum balances of the addrs passed, in the same
+// order, at the block carried by the context. The balances are read using
+// JSON-RPC batches of the batch size of the client. If some of the balances
+// cannot be read, their balances are nil and an *ErrBatchFailed is returned.
+// Batches are not read with a Quorum, so every batch is read from a single
+// endpoint even if the client has a quorum.
+func (client *Client) BalancesOf(ctx context.Context, addrs []common.Address) ([]*big.Int, error) {
+	block := blockRef(ctx).arg()
+	balances := make([]hexutil.Big, len(addrs))
+	requests := make([]RPCRequest, len(addrs))
+	for i, addr := range addrs {
+		requests[i] = RPCRequest{
+			Method: "eth_getBalance",
+			Params: []interface{}{addr, block},
+			Result: &balances[i],
+		}
+	}
+	if err := client.batchRead(ctx, requests); err != nil {
+		return nil, err
+	}
+
+	vals := make([]*big.Int, len(addrs))
+	for i := range requests {
+		if requests[i].Err == nil {
+			vals[i] = balances[i].ToInt()
+		}
+	}
+	return vals, batchErr(requests)
+}
+
+// batchRead sends the requests in JSON-RPC batches of the batch size of the
+// client. Requests that fail, and requests in batches that cannot be sent, are
+// retried according to the RetryPolicy of the client, without sending the
+// requests that have already been read again. Requests keep their error once
+// the policy stops retrying. An error is only returned if a batch cannot be
+// sent.
+func (client *Client) batchRead(ctx context.Context, requests []RPCRequest) error {
+	if !client.hasEndpoint() {
+		return ErrNoEndpoints
+	}
+	size := client.batchSize
+	if size <= 0 {
+		size = DefaultBatchSize
+	}
+
+	pending := make([]int, len(requests))
+	for i := range requests {
+		pending[i] = i
+	}
+	attempt := 1
+	sent := false
+	err := client.Get(ctx, func() error {
+		sent = false
+		failed := []int{}
+		for start := 0; start < len(pending); start += size {
+			end := start + size
+			if end > len(pending) {
+				end = len(pending)
+			}
+			batch := make([]RPCRequest, end-start)
+			for i, index := range pending[start:end] {
+				batch[i] = requests[index]
+				batch[i].Err = nil
+			}
+			client.log().Debug("sending batch", "size", len(batch))
+			if err := client.BatchCall(ctx, batch); err != nil {
+				// Only the failed reads, and the reads that have not been
+				// sent, are retried
+				pending = append(failed, pending[start:]...)
+				return err
+			}
+			for i, index := range pending[start:end] {
+				requests[index].Err = batch[i].Err
+				if batch[i].Err != nil {
+					failed = append(failed, index)
+				}
+			}
+		}
+		pending, sent = failed, true
+
+		// Failed reads are retried in the next attempt, but do not fail the
+		// batch once the policy stops retrying
+		policy := retryPolicy(ctx, client.retryPolicy)
+		if len(pending) == 0 || policy.exhausted(attempt) || !policy.retryable(requests[pending[0]].Err) {
+			return nil
+		}
+		attempt++
+		return requests[pending[0]].Err
+	})
+	if err != nil && sent {
+		// The context is done while failed reads are retried
+		return nil
+	}
+	return err
+}
+
+// batchErr returns an *ErrBatchFailed with the errors of the requests, or nil
+// if none of them failed.
+func batchErr(requests []RPCRequest) error {
+	errs := map[int]error{}
+	for i, request := range requests {
+		if request.Err != nil {
+			errs[i] = request.Err
+		}
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return &ErrBatchFailed{Errs: errs, Total: len(requests)}
+}
@@ -0,0 +1,155 @@
+package beth_test
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/republicprotocol/beth-go"
+)
+
+var _ = Describe("batched reads", func() {
+
+	var chain *fakeChain
+	var counter *countingHandler
+	var server *httptest.Server
+	var ctx context.Context
+	var cancel context.CancelFunc
+
+	// addresses returns n addresses whose balances are their index.
+	addresses := func(n int) []common.Address {
+		addrs := make([]common.Address, n)
+		for i := range addrs {
+			addrs[i] = common.BigToAddress(big.NewInt(int64(i + 1)))
+			chain.balances[addrs[i]] = big.NewInt(int64(i))
+		}
+		return addrs
+	}
+
+	BeforeEach(func() {
+		chain = newFakeChain(100)
+		counter = &countingHandler{handler: chain}
+		server = httptest.NewServer(counter)
+		ctx, cancel = context.WithTimeout(context.Background(), 5*time.Second)
+		ctx = beth.WithRetryPolicy(ctx, beth.RetryPolicy{InitialDelay: time.Millisecond, MaxAttempts: 3})
+	})
+
+	AfterEach(func() {
+		cancel()
+		server.Close()
+	})
+
+	Context("when reading the balances of many addresses", func() {
+		It("should read them in batches of the batch size", func() {
+			client, err := beth.Connect(server.URL)
+			Expect(err).ShouldNot(HaveOccurred())
+			client.SetBatchSize(10)
+			addrs := addresses(25)
+
+			requests := counter.count()
+			balances, err := client.BalancesOf(ctx, addrs)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(counter.count() - requests).Should(Equal(int64(3)))
+			for i, balance := range balances {
+				Expect(balance.Int64()).Should(Equal(int64(i)))
+			}
+		})
+
+		It("should only resend the batches that cannot be sent", func() {
+			// The second batch cannot be sent until the retry policy of the
+			// batch stops retrying, so the read is retried
+			requests := int64(0)
+			failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if n := atomic.AddInt64(&requests, 1); n >= 2 && n <= 4 {
+					http.Error(w, "unavailable", http.StatusServiceUnavailable)
+					return
+				}
+				chain.ServeHTTP(w, r)
+			}))
+			defer failing.Close()
+			client, err := beth.Connect(failing.URL)
+			Expect(err).ShouldNot(HaveOccurred())
+			client.SetBatchSize(10)
+			addrs := addresses(25)
+
+			atomic.StoreInt64(&requests, 0)
+			balances, err := client.BalancesOf(ctx, addrs)
+			Expect(err).ShouldNot(HaveOccurred())
+			for i, balance := range balances {
+				Expect(balance.Int64()).Should(Equal(int64(i)))
+			}
+			chain.mu.Lock()
+			defer chain.mu.Unlock()
+			Expect(chain.balanceBlocks).Should(HaveLen(25))
+		})
+
+		It("should retry the reads that fail", func() {
+			client, err := beth.Connect(server.URL)
+			Expect(err).ShouldNot(HaveOccurred())
+			addrs := addresses(5)
+			chain.balanceFailures[addrs[2]] = 1
+
+			balances, err := client.BalancesOf(ctx, addrs)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(balances[2].Int64()).Should(Equal(int64(2)))
+		})
+
+		It("should report the reads that keep failing", func() {
+			client, err := beth.Connect(server.URL)
+			Expect(err).ShouldNot(HaveOccurred())
+			addrs := addresses(5)
+			chain.balanceFailures[addrs[1]] = -1
+			chain.balanceFailures[addrs[3]] = -1
+
+			balances, err := client.BalancesOf(ctx, addrs)
+			var batchErr *beth.ErrBatchFailed
+			Expect(errors.As(err, &batchErr)).Should(BeTrue())
+			Expect(batchErr.Total).Should(Equal(5))
+			Expect(batchErr.Errs).Should(HaveLen(2))
+			Expect(batchErr.Errs).Should(HaveKey(1))
+			Expect(batchErr.Errs).Should(HaveKey(3))
+
+			Expect(balances).Should(HaveLen(5))
+			Expect(balances[1]).Should(BeNil())
+			Expect(balances[3]).Should(BeNil())
+			Expect(balances[4].Int64()).Should(Equal(int64(4)))
+		})
+	})
+
+	Context("when reading the token balances of many holders", func() {
+		It("should read them in batches", func() {
+			key, err := crypto.GenerateKey()
+			Expect(err).ShouldNot(HaveOccurred())
+			account, err := beth.NewAccount(server.URL, key, beth.NewFixedGasPriceOracle(big.NewInt(1)))
+			Expect(err).ShouldNot(HaveOccurred())
+			account.SetBatchSize(4)
+			token, err := account.NewERC20("0x0000000000000000000000000000000000000abc")
+			Expect(err).ShouldNot(HaveOccurred())
+			addrs := addresses(8)
+			chain.balanceFailures[addrs[5]] = -1
+
+			requests := counter.count()
+			balances, err := token.BalancesOf(ctx, addrs)
+			var batchErr *beth.ErrBatchFailed
+			Expect(errors.As(err, &batchErr)).Should(BeTrue())
+			Expect(batchErr.Errs).Should(HaveKey(5))
+			Expect(counter.count() - requests).Should(Equal(int64(4)))
+			for i, balance := range balances {
+				if i == 5 {
+					Expect(balance).Should(BeNil())
+					continue
+				}
+				Expect(balance.Int64()).Should(Equal(int64(i)))
+			}
+		})
+	})
+})
@@ -4,63 +4,39 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"io/ioutil"
-	"math"
 	"math/big"
 	"math/rand"
-	"os"
+	"net/http/httptest"
+	"sync"
 	"time"
 
-	"github.com/ethereum/go-ethereum/crypto"
-
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
-	"github.com/ethereum/go-ethereum/accounts/keystore"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/republicprotocol/beth-go"
 	"github.com/republicprotocol/beth-go/test"
-	"github.com/republicprotocol/co-go"
 )
 
 var _ = Describe("contracts", func() {
 
-	newAccount := func(network, keystorePath string, passphrase string) (beth.Account, error) {
-		// Open keystore file
-		keyin, err := os.Open(keystorePath)
-		if err != nil {
-			return nil, err
-		}
-		data, err := ioutil.ReadAll(keyin)
-		if err != nil {
-			return nil, err
-		}
+	var chain *simulatedChain
 
-		// Parse the json data to a key object
-		key, err := keystore.DecryptKey(data, passphrase)
-		if err != nil {
-			return nil, err
-		}
+	BeforeEach(func() {
+		chain = newSimulatedChain(2)
+	})
 
-		return beth.NewAccount(fmt.Sprintf("https://%s.infura.io", network), key.PrivateKey)
-	}
+	AfterEach(func() {
+		chain.close()
+	})
 
-	bethTest := func(network string, account beth.Account) (*test.Bethtest, error) {
-		// Ropsten : 0x46bcff69b2d5a677c40c05c1f034ef7bf0ee4742
-		// Kovan : 0x055d30956deea82bfe0f99a2771dcc36a18dc9bb
-		contractAddr := common.Address{}
-		switch network {
-		case "kovan":
-			contractAddr = common.HexToAddress("0xaf866d7f173115e4cd5401f2abadb5b26eae8c32")
-		case "ropsten":
-			contractAddr = common.HexToAddress("0xd842576402d06f9985f407a5fd74e3eb06584110")
-		default:
-			return nil, errors.New("invalid infura network")
-		}
-		// Get contract
-		return test.NewBethtest(contractAddr, bind.ContractBackend(account.EthClient()))
+	bethTest := func(account beth.Account) *test.Bethtest {
+		contract, err := test.NewBethtest(chain.bethtest, bind.ContractBackend(account.EthClient()))
+		Expect(err).ShouldNot(HaveOccurred())
+		return contract
 	}
 
 	elementExists := func(ctx context.Context, conn beth.Client, contract *test.Bethtest, val *big.Int) (exists bool) {
@@ -72,20 +48,20 @@ var _ = Describe("contracts", func() {
 		return
 	}
 
-	read := func(ctx context.Context, conn beth.Client, contract *test.Bethtest) (*big.Int, error) {
-		newVal, err := contract.Read(&bind.CallOpts{})
-		if err != nil {
-			return nil, err
-		}
-		fmt.Printf("[info] Value in contract is %v\n", newVal.String())
-		return newVal, nil
+	read := func(contract *test.Bethtest) (*big.Int, error) {
+		return contract.Read(&bind.CallOpts{})
 	}
 
-	setInt := func(account beth.Account, contract *test.Bethtest, val *big.Int, waitBlocks int64) error {
-
-		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
-		defer cancel()
+	size := func(ctx context.Context, conn beth.Client, contract *test.Bethtest) (size *big.Int, err error) {
+		size = big.NewInt(0)
+		err = conn.Get(ctx, func() (err error) {
+			size, err = contract.Size(&bind.CallOpts{})
+			return
+		})
+		return
+	}
 
+	setInt := func(ctx context.Context, account beth.Account, contract *test.Bethtest, val *big.Int) error {
 		// Set integer in contract
 		f := func(txOpts *bind.TransactOpts) (*types.Transaction, error) {
 			return contract.Set(txOpts, val)
@@ -93,20 +69,18 @@ var _ = Describe("contracts", func() {
 
 		// Post-condition: Confirm that the integer has the new value
 		postCondition := func() bool {
-			newVal, err := read(ctx, account.Client(), contract)
+			newVal, err := read(contract)
 			if err != nil {
 				return false
 			}
 			return newVal.Cmp(val) == 0
 		}
 
-		return account.Transact(ctx, nil, f, postCondition, waitBlocks)
+		_, err := account.Transact(ctx, nil, f, postCondition, 0)
+		return err
 	}
 
-	increment := func(account beth.Account, contract *test.Bethtest, val *big.Int, waitBlocks int64) error {
-		ctx, cancel := context.WithTimeout(context.Background(), time.Duration(10)*time.Minute)
-		defer cancel()
-
+	increment := func(ctx context.Context, account beth.Account, contract *test.Bethtest, val *big.Int) error {
 		val.Add(val, big.NewInt(1))
 
 		// Increment integer in the contract
@@ -116,36 +90,39 @@ var _ = Describe("contracts", func() {
 
 		// Post-condition: confirm that previous value has been incremented
 		postCondition := func() bool {
-			newVal, err := read(ctx, account.Client(), contract)
+			newVal, err := read(contract)
 			if err != nil {
 				return false
 			}
 			return newVal.Cmp(val) >= 0
 		}
 
-		return account.Transact(ctx, nil, f, postCondition, waitBlocks)
+		_, err := account.Transact(ctx, nil, f, postCondition, 0)
+		return err
 	}
 
-	appendToList := func(values []*big.Int, contract *test.Bethtest, account beth.Account, waitBlocks int64) []error {
-		ctx, cancel := context.WithTimeout(context.Background(), time.Duration((len(values)*10)+int(waitBlocks))*time.Minute)
-		defer cancel()
-
+	// forAll calls f for every value in parallel, and returns the errors that
+	// it returns.
+	forAll := func(values []*big.Int, f func(val *big.Int) error) []error {
 		errs := make([]error, len(values))
+		var wg sync.WaitGroup
+		for i := range values {
+			wg.Add(1)
+			go func(i int) {
+				defer GinkgoRecover()
+				defer wg.Done()
+				errs[i] = f(values[i])
+			}(i)
+		}
+		wg.Wait()
+		return errs
+	}
 
-		co.ParForAll(values, func(i int) {
-			defer GinkgoRecover()
-			val := values[i]
-
-			fmt.Printf("\n\x1b[37;1mAppending %v to list\x1b[0m", val.String())
-
+	appendToList := func(ctx context.Context, values []*big.Int, contract *test.Bethtest, account beth.Account) []error {
+		return forAll(values, func(val *big.Int) error {
 			// Pre-condition: Does element already exist in the list?
 			preCondition := func() bool {
-				exists := elementExists(ctx, account.Client(), contract, val)
-				if exists {
-					fmt.Printf("\n[warning] Element %v exists in list!\n", val.String())
-				}
-
-				return !exists
+				return !elementExists(ctx, account.Client(), contract, val)
 			}
 
 			// Append to list
@@ -159,50 +136,20 @@ var _ = Describe("contracts", func() {
 			}
 
 			// Execute transaction
-			errs[i] = account.Transact(ctx, preCondition, f, postCondition, waitBlocks)
+			_, err := account.Transact(ctx, preCondition, f, postCondition, 0)
+			return err
 		})
-
-		return errs
 	}
 
-	size := func(ctx context.Context, conn beth.Client, contract *test.Bethtest) (size *big.Int, err error) {
-		size = big.NewInt(0)
-		err = conn.Get(ctx, func() (err error) {
-			size, err = contract.Size(&bind.CallOpts{})
-			return
-		})
-		return
-	}
-
-	deleteFromList := func(values []*big.Int, contract *test.Bethtest, account beth.Account, waitBlocks int64) []error {
-		// Context
-		ctx, cancel := context.WithTimeout(context.Background(), time.Duration((len(values)*10)+int(waitBlocks))*time.Minute)
-		defer cancel()
-
-		errs := make([]error, len(values))
-
-		co.ParForAll(values, func(i int) {
-			defer GinkgoRecover()
-			val := values[i]
-			fmt.Printf("\n\x1b[37;1mDeleting %v from list\x1b[0m", val.String())
-
+	deleteFromList := func(ctx context.Context, values []*big.Int, contract *test.Bethtest, account beth.Account) []error {
+		return forAll(values, func(val *big.Int) error {
 			// Pre-condition: is list is empty or is element absent in list?
 			preCondition := func() bool {
-
-				// Read size of list
 				size, err := size(ctx, account.Client(), contract)
 				if err != nil || size.Cmp(big.NewInt(0)) <= 0 {
-					fmt.Println("\n[warning] list is empty!")
 					return false
 				}
-
-				// Check if element is present
-				exists := elementExists(ctx, account.Client(), contract, val)
-				if !exists {
-					fmt.Printf("\n[warning] %v is not present in list!\n", val.String())
-				}
-
-				return exists
+				return elementExists(ctx, account.Client(), contract, val)
 			}
 
 			// Remove element
@@ -216,259 +163,276 @@ var _ = Describe("contracts", func() {
 			}
 
 			// Execute delete tx
-			errs[i] = account.Transact(ctx, preCondition, f, postCondition, waitBlocks)
+			_, err := account.Transact(ctx, preCondition, f, postCondition, 0)
+			return err
 		})
-
-		return errs
 	}
 
-	randomValues := func(n int64) []*big.Int {
+	randomValues := func(n int) []*big.Int {
 		values := []*big.Int{}
 		uniqueValues := make(map[int]struct{})
-		i := 0
-		for i < int(n) {
+		for len(values) < n {
 			// Randomly create a value and append it to list
 			integerValue := rand.Intn(10000) + 1
 			if _, ok := uniqueValues[integerValue]; ok {
 				continue
 			}
 			uniqueValues[integerValue] = struct{}{}
-
-			val := big.NewInt(int64(integerValue))
-			values = append(values, val)
-			i++
+			values = append(values, big.NewInt(int64(integerValue)))
 		}
 		return values
 	}
 
-	// handleErrors loop through a list of errors. Calculate how many errors is not
-	// nil and return the last non-nil error if exists.
+	// handleErrors returns the last error that is not nil, if it exists.
 	handleErrors := func(errs []error) error {
-		var counter int
 		var err error
-
 		for i := range errs {
 			if errs[i] != nil {
-				counter++
 				err = errs[i]
 			}
 		}
 		return err
 	}
 
-	loadAddressBook := func(network string) beth.AddressBook {
-		switch network {
-		case "ropsten":
-			return beth.DefaultAddressBook(3)
-		case "kovan":
-			return beth.DefaultAddressBook(42)
-		default:
-			return beth.AddressBook{}
-		}
-	}
+	Context("when modifying an integer in a contract", func() {
+		It("should write to the contract and not return an error", func() {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+			defer cancel()
 
-	rand.Seed(time.Now().Unix())
-	testedNetworks := []string{"ropsten", "kovan"}
+			account := chain.newAccount(0)
+			contract := bethTest(account)
+			val := big.NewInt(int64(rand.Intn(100)))
 
-	keystorePaths := []string{"test/keystore.ropsten.json", "test/keystore.kovan.json"}
-	addresses := []string{"3a5e0b1158ca9ce861a80c3049d347a3f1825db0", "6b9b3e47c4c73db44f6a34064b21da8c62692a8c"}
-
-	tableParallelism := []struct {
-		n, waitBlocks int64
-	}{
-		{1, 3},
-		// {2, 3},
-		// {4, 2},
-		// {8, 1},
-		// {16, 1},
-	}
+			nonceBefore, err := account.EthClient().NonceAt(ctx, account.Address(), nil)
+			Expect(err).ShouldNot(HaveOccurred())
 
-	for _, network := range testedNetworks {
-		network := network
+			// Set value in the contract
+			Expect(setInt(ctx, account, contract, val)).Should(Succeed())
 
-		for ind, entry := range tableParallelism {
-			n := entry.n
-			waitBlocks := entry.waitBlocks
-
-			// restrict ropsten tests
-			if network == "ropsten" {
-				if ind != 0 {
-					continue
-				}
-				waitBlocks = 0
-			}
+			nonceMid, err := account.EthClient().NonceAt(ctx, account.Address(), nil)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(nonceMid - nonceBefore).Should(Equal(uint64(1)))
 
-			Context(fmt.Sprintf("when modifying an integer %v times in a contract deployed on %s", n, network), func() {
+			// Increment the value in the contract
+			Expect(increment(ctx, account, contract, val)).Should(Succeed())
 
-				It("should write to the contract and not return an error", func() {
-					for i := 0; i < int(n); i++ {
+			nonceAfter, err := account.EthClient().NonceAt(ctx, account.Address(), nil)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(nonceAfter - nonceMid).Should(Equal(uint64(1)))
 
-						account, err := newAccount(network, fmt.Sprintf("test/keystore.%s.json", network), os.Getenv("passphrase"))
-						Expect(err).ShouldNot(HaveOccurred())
-
-						contract, err := bethTest(network, account)
-						Expect(err).ShouldNot(HaveOccurred())
-
-						// Generate random value
-						val := big.NewInt(int64(rand.Intn(100)))
-
-						fmt.Printf("\n\x1b[37;1mSetting integer %v in the contract on %s\n\x1b[0m", val.String(), network)
-
-						nonceBefore, err := account.EthClient().NonceAt(context.Background(), account.Address(), nil)
-						Expect(err).ShouldNot(HaveOccurred())
-
-						// Set value in the contract
-						err = setInt(account, contract, val, waitBlocks)
-						Expect(err).ShouldNot(HaveOccurred())
+			newVal, err := read(contract)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(newVal).Should(Equal(val))
+		})
+	})
+
+	Context("when updating a list in a contract", func() {
+		It("should write to the contract and not return an error", func() {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+			defer cancel()
+
+			account := chain.newAccount(0)
+			contract := bethTest(account)
+
+			// Append randomly generated values to a list maintained by a smart contract
+			values := randomValues(4)
+			Expect(handleErrors(appendToList(ctx, values, contract, account))).ShouldNot(HaveOccurred())
+			length, err := size(ctx, account.Client(), contract)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(length.Int64()).Should(Equal(int64(len(values))))
+
+			// Attempt to add a previously added item again
+			err = handleErrors(appendToList(ctx, values[:1], contract, account))
+			Expect(err).Should(Equal(beth.ErrPreConditionCheckFailed))
+
+			// Attempt to delete all newly added elements from the list
+			Expect(handleErrors(deleteFromList(ctx, values, contract, account))).ShouldNot(HaveOccurred())
+
+			// Attempt to delete a value that does not exist in the list
+			err = handleErrors(deleteFromList(ctx, values[:1], contract, account))
+			Expect(err).Should(Equal(beth.ErrPreConditionCheckFailed))
+
+			// Retrieve length of array after deleting the newly added elements
+			length, err = size(ctx, account.Client(), contract)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(length.Int64()).Should(Equal(int64(0)))
+		})
+	})
 
-						nonceMid, err := account.EthClient().NonceAt(context.Background(), account.Address(), nil)
-						Expect(err).ShouldNot(HaveOccurred())
-						Expect(nonceMid - nonceBefore).Should(Equal(uint64(1)))
+	Context("when transferring eth from one account to an ethereum address", func() {
+		It("should successfully transfer eth and not return an error", func() {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+			defer cancel()
 
-						fmt.Printf("\n\x1b[37;1mIncrementing %v in the contract on %s\x1b[0m\n", val.String(), network)
+			from, to := chain.newAccount(0), chain.newAccount(1)
+			value := big.NewInt(1e18)
+			_, err := from.Transfer(ctx, to.Address(), value, nil, 0, false)
+			Expect(err).ShouldNot(HaveOccurred())
 
-						// Increment the value in the contract
-						err = increment(account, contract, val, waitBlocks)
-						Expect(err).ShouldNot(HaveOccurred())
+			balance, err := to.BalanceAt(ctx, nil)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(balance).Should(Equal(new(big.Int).Add(simulatedChainFunds, value)))
+		})
 
-						nonceAfter, err := account.EthClient().NonceAt(context.Background(), account.Address(), nil)
-						Expect(err).ShouldNot(HaveOccurred())
-						Expect(nonceAfter - nonceMid).Should(Equal(uint64(1)))
-					}
-				})
-			})
+		It("should transfer the whole balance if asked to", func() {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+			defer cancel()
 
-			Context(fmt.Sprintf("when updating a list with %v elements in a contract deployed on %s", n, network), func() {
+			from, to := chain.newAccount(1), chain.newAccount(0)
+			_, err := from.Transfer(ctx, to.Address(), nil, nil, 0, true)
+			Expect(err).ShouldNot(HaveOccurred())
 
-				It("should write to the contract and not return an error", func() {
+			balance, err := from.BalanceAt(ctx, nil)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(balance.Cmp(big.NewInt(1e15))).Should(BeNumerically("<", 0))
+		})
+	})
+
+	Context("when transferring tokens", func() {
+		It("should update the balances and allowances of the holders", func() {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+			defer cancel()
+
+			owner, spender := chain.newAccount(0), chain.newAccount(1)
+			ownerToken, err := owner.NewERC20(chain.erc20.Hex())
+			Expect(err).ShouldNot(HaveOccurred())
+			spenderToken, err := spender.NewERC20(chain.erc20.Hex())
+			Expect(err).ShouldNot(HaveOccurred())
+			recipient := common.HexToAddress("0x0000000000000000000000000000000000000001")
+
+			_, err = ownerToken.Transfer(ctx, spender.Address(), big.NewInt(100), nil, false)
+			Expect(err).ShouldNot(HaveOccurred())
+			_, err = ownerToken.Approve(ctx, spender.Address(), big.NewInt(50), nil)
+			Expect(err).ShouldNot(HaveOccurred())
+			_, err = spenderToken.TransferFrom(ctx, owner.Address(), recipient, big.NewInt(30), nil)
+			Expect(err).ShouldNot(HaveOccurred())
+
+			allowance, err := ownerToken.Allowance(ctx, owner.Address(), spender.Address())
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(allowance.Int64()).Should(Equal(int64(20)))
+
+			balances, err := ownerToken.BalancesOf(ctx, []common.Address{owner.Address(), spender.Address(), recipient})
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(balances).Should(Equal([]*big.Int{
+				new(big.Int).Sub(simulatedChainFunds, big.NewInt(130)),
+				big.NewInt(100),
+				big.NewInt(30),
+			}))
+
+			// Send all of the remaining balance
+			_, err = spenderToken.Transfer(ctx, recipient, nil, nil, true)
+			Expect(err).ShouldNot(HaveOccurred())
+			balance, err := spenderToken.BalanceOf(ctx, recipient)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(balance.Int64()).Should(Equal(int64(130)))
+		})
 
-					account, err := newAccount(network, fmt.Sprintf("test/keystore.%s.json", network), os.Getenv("passphrase"))
-					Expect(err).ShouldNot(HaveOccurred())
-					contract, err := bethTest(network, account)
-					Expect(err).ShouldNot(HaveOccurred())
+		It("should return an ErrReverted if the balance is too low", func() {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+			defer cancel()
+
+			account := chain.newAccount(1)
+			token, err := beth.NewCompatibleERC20(chain.erc20, bind.ContractBackend(account.EthClient()))
+			Expect(err).ShouldNot(HaveOccurred())
+
+			// Set the gas limit, so that the transaction is sent without
+			// estimating its gas
+			_, err = account.Transact(ctx, nil, func(txOpts *bind.TransactOpts) (*types.Transaction, error) {
+				txOpts.GasLimit = 100000
+				return token.Transfer(txOpts, account.Address(), big.NewInt(1))
+			}, nil, 0)
+			var reverted *beth.ErrReverted
+			Expect(errors.As(err, &reverted)).Should(BeTrue())
+		})
+	})
+
+	Context("when reading block numbers", func() {
+		It("should return the block of a transaction and the current block", func() {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+			defer cancel()
+
+			account := chain.newAccount(0)
+			tx, err := account.Transfer(ctx, common.Address{}, big.NewInt(1), nil, 2, false)
+			Expect(err).ShouldNot(HaveOccurred())
+
+			client := account.Client()
+			txBlock, err := client.TxBlockNumber(ctx, tx.Hash().Hex())
+			Expect(err).ShouldNot(HaveOccurred())
+			current, err := client.CurrentBlockNumber(ctx)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(new(big.Int).Sub(current, txBlock).Int64()).Should(BeNumerically(">=", 2))
+		})
+	})
 
-					// Retrieve original length of array
-					originalLength, err := size(context.Background(), account.Client(), contract)
-					Expect(err).ShouldNot(HaveOccurred())
-					fmt.Printf("\n\x1b[37;1mThe size of array on %s is %v\n\x1b[0m", network, originalLength.String())
+	Context("when reading addresses", func() {
+		It("should return the addresses written to the address book", func() {
+			account := chain.newAccount(0)
+			account.WriteAddress("Bethtest", chain.bethtest)
 
-					// Append randomly generated values to a list maintained by a smart contract
-					values := randomValues(n)
-					errs := appendToList(values, contract, account, waitBlocks)
-					err = handleErrors(errs)
-					if err != nil && err != beth.ErrPreConditionCheckFailed {
-						Expect(err).ShouldNot(HaveOccurred())
-					}
+			address, err := account.ReadAddress("Bethtest")
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(address).Should(Equal(chain.bethtest))
 
-					// Attempt to add a previously added item again
-					errs = appendToList(values[:1], contract, account, waitBlocks)
-					err = handleErrors(errs)
-					Expect(err).Should(HaveOccurred())
-					Expect(err).Should(Equal(beth.ErrPreConditionCheckFailed))
+			_, err = account.ReadAddress("RenExOrderbook")
+			Expect(err).Should(Equal(beth.ErrAddressNotFound))
+		})
+	})
 
-					// Attempt to delete all newly added elements from the list
-					errs = deleteFromList(values, contract, account, waitBlocks)
-					Expect(handleErrors(errs)).ShouldNot(HaveOccurred())
+	for network, networkID := range map[string]string{"ropsten": "3", "kovan": "42"} {
+		network, networkID := network, networkID
 
-					// Attempt to delete a value that does not exist in the list
-					errs = deleteFromList(values[:1], contract, account, waitBlocks)
-					err = handleErrors(errs)
-					Expect(err).Should(HaveOccurred())
-					Expect(err).Should(Equal(beth.ErrPreConditionCheckFailed))
+		Context(fmt.Sprintf("when retrieving addresses on %s", network), func() {
+			var server *httptest.Server
+			var addrBook beth.AddressBook
 
-					// Retrieve length of array after deleting the newly added elements
-					newLength, err := size(context.Background(), account.Client(), contract)
-					Expect(err).ShouldNot(HaveOccurred())
-					fmt.Printf("\n\x1b[37;1mThe new size of array on %s is %v\n\x1b[0m", network, newLength.String())
+			BeforeEach(func() {
+				fake := newFakeChain(100)
+				fake.network = networkID
+				server = httptest.NewServer(fake)
 
-					// The new length must not be greater than the original length
-					Expect(newLength.Cmp(originalLength)).To(BeNumerically("<=", 0))
-				})
+				id, ok := new(big.Int).SetString(networkID, 10)
+				Expect(ok).Should(BeTrue())
+				addrBook = beth.DefaultAddressBook(id.Int64())
 			})
 
-			Context(fmt.Sprintf("when transferring eth from one account to an ethereum address on %s", network), func() {
-
-				It("should successfully transfer eth and not return an error", func() {
-					// Context with 10 minute timeout
-					ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
-					defer cancel()
-
-					toAddrs := []common.Address{}
-					for i := 0; i < 2; i++ {
-						toAddrs = append(toAddrs, common.HexToAddress(addresses[i]))
-					}
-
-					co.ParForAll(toAddrs, func(i int) {
-						account, err := newAccount(network, keystorePaths[i], os.Getenv("passphrase"))
-						Expect(err).ShouldNot(HaveOccurred())
-						// Transfer 1 Eth to the other account's address
-						value, _ := big.NewFloat(1 * math.Pow10(18)).Int(nil)
-						if _, err := account.Transfer(ctx, toAddrs[i], value, waitBlocks); err != nil {
-							Expect(err).ShouldNot(HaveOccurred())
-						}
-					})
-				})
+			AfterEach(func() {
+				server.Close()
 			})
 
-			Context(fmt.Sprintf("when retrieving addresses on %s", network), func() {
+			for _, name := range []string{"RenExOrderbook", "RenExSettlement", "ERC20:WBTC", "Swapper:ETH", "Swapper:WBTC"} {
+				name := name
 
-				It("should successfully return the address of RenExOrderbook", func() {
-					addrBook := loadAddressBook(network)
-					account, err := newAccount(network, keystorePaths[0], os.Getenv("passphrase"))
+				It(fmt.Sprintf("should return the address of %s from the address book of the network", name), func() {
+					key, err := crypto.GenerateKey()
 					Expect(err).ShouldNot(HaveOccurred())
-					renExOrderbook, err := account.ReadAddress("RenExOrderbook")
-					Expect(renExOrderbook.String()).Should(Equal(addrBook["RenExOrderbook"].String()))
-				})
-
-				It("should successfully return the address of RenExSettlement", func() {
-					addrBook := loadAddressBook(network)
-					account, err := newAccount(network, keystorePaths[0], os.Getenv("passphrase"))
+					account, err := beth.NewAccount(server.URL, key, beth.NewFixedGasPriceOracle(big.NewInt(10e9)))
 					Expect(err).ShouldNot(HaveOccurred())
-					renExSettlement, err := account.ReadAddress("RenExSettlement")
-					Expect(renExSettlement.String()).Should(Equal(addrBook["RenExSettlement"].String()))
-				})
-
-				It("should successfully return the address of ERC20:WBTC", func() {
-					addrBook := loadAddressBook(network)
-					account, err := newAccount(network, keystorePaths[0], os.Getenv("passphrase"))
-					Expect(err).ShouldNot(HaveOccurred())
-					ERC20WBTC, err := account.ReadAddress("ERC20:WBTC")
-					Expect(ERC20WBTC.String()).Should(Equal(addrBook["ERC20:WBTC"].String()))
-				})
-
-				It("should successfully return the address of Swapper:ETH", func() {
-					addrBook := loadAddressBook(network)
-					account, err := newAccount(network, keystorePaths[0], os.Getenv("passphrase"))
-					Expect(err).ShouldNot(HaveOccurred())
-					SwapperETH, err := account.ReadAddress("Swapper:ETH")
-					Expect(SwapperETH.String()).Should(Equal(addrBook["Swapper:ETH"].String()))
-				})
 
-				It("should successfully return the address of Swapper:WBTC", func() {
-					addrBook := loadAddressBook(network)
-					account, err := newAccount(network, keystorePaths[0], os.Getenv("passphrase"))
-					Expect(err).ShouldNot(HaveOccurred())
-					SwapperWBTC, err := account.ReadAddress("Swapper:WBTC")
-					Expect(SwapperWBTC.String()).Should(Equal(addrBook["Swapper:WBTC"].String()))
+					address, err := account.ReadAddress(name)
+					if expected, ok := addrBook[name]; ok {
+						Expect(err).ShouldNot(HaveOccurred())
+						Expect(address).Should(Equal(expected))
+					} else {
+						Expect(err).Should(Equal(beth.ErrAddressNotFound))
+					}
 				})
-			})
+			}
+		})
+	}
 
-			Context("when signing messages", func() {
-				It("should successfully sign a message", func() {
-					account, err := newAccount(network, keystorePaths[0], os.Getenv("passphrase"))
-					Expect(err).ShouldNot(HaveOccurred())
+	Context("when signing messages", func() {
+		It("should successfully sign a message", func() {
+			account := chain.newAccount(0)
 
-					msgHash := crypto.Keccak256([]byte("Message"))
-					sig, err := account.Sign(msgHash)
-					Expect(err).ShouldNot(HaveOccurred())
+			msgHash := crypto.Keccak256([]byte("Message"))
+			sig, err := account.Sign(msgHash)
+			Expect(err).ShouldNot(HaveOccurred())
 
-					publicKey, err := crypto.SigToPub(msgHash, sig)
-					Expect(err).ShouldNot(HaveOccurred())
+			publicKey, err := crypto.SigToPub(msgHash, sig)
+			Expect(err).ShouldNot(HaveOccurred())
 
-					signerAddress := crypto.PubkeyToAddress(*publicKey)
-					Expect(signerAddress.String()).Should(Equal(account.Address().String()))
-				})
-			})
-		}
-	}
+			signerAddress := crypto.PubkeyToAddress(*publicKey)
+			Expect(signerAddress.String()).Should(Equal(account.Address().String()))
+		})
+	})
 })
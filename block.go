@@ -0,0 +1,87 @@
+package beth
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// BlockRef refers to the block at which state is read. It can be a block
+// number, a block hash, or one of the LatestBlock, PendingBlock, SafeBlock and
+// FinalizedBlock tags.
+type BlockRef struct {
+	number *big.Int
+	hash   *common.Hash
+	tag    string
+}
+
+// BlockRef tags.
+var (
+	LatestBlock    = BlockRef{tag: "latest"}
+	PendingBlock   = BlockRef{tag: "pending"}
+	SafeBlock      = BlockRef{tag: "safe"}
+	FinalizedBlock = BlockRef{tag: "finalized"}
+)
+
+// BlockAtNumber refers to the block with the given number in the canonical
+// chain. A nil number refers to the LatestBlock.
+func BlockAtNumber(number *big.Int) BlockRef {
+	if number == nil {
+		return LatestBlock
+	}
+	return BlockRef{number: new(big.Int).Set(number)}
+}
+
+// BlockAtHash refers to the block with the given hash. Reading state at the
+// block fails if the block is not in the canonical chain.
+func BlockAtHash(hash common.Hash) BlockRef {
+	return BlockRef{hash: &hash}
+}
+
+// String returns the block number, the block hash, or the tag of the block.
+func (block BlockRef) String() string {
+	switch {
+	case block.number != nil:
+		return block.number.String()
+	case block.hash != nil:
+		return block.hash.Hex()
+	case block.tag != "":
+		return block.tag
+	}
+	return LatestBlock.tag
+}
+
+// arg returns the JSON-RPC block parameter of the block. Blocks are referred
+// to by hash using EIP-1898.
+func (block BlockRef) arg() interface{} {
+	switch {
+	case block.number != nil:
+		return hexutil.EncodeBig(block.number)
+	case block.hash != nil:
+		return map[string]interface{}{"blockHash": *block.hash, "requireCanonical": true}
+	case block.tag != "":
+		return block.tag
+	}
+	return LatestBlock.tag
+}
+
+// blockKey is the context key of a BlockRef.
+type blockKey struct{}
+
+// WithBlock returns a copy of the context that carries the block. Reads made
+// with the context by a Client, an Account or an ERC20 read state at the block
+// instead of at the latest block. It has no effect on the reads made by the
+// function passed to Client.Get.
+func WithBlock(ctx context.Context, block BlockRef) context.Context {
+	return context.WithValue(ctx, blockKey{}, block)
+}
+
+// blockRef returns the block carried by the context, or the LatestBlock.
+func blockRef(ctx context.Context) BlockRef {
+	if block, ok := ctx.Value(blockKey{}).(BlockRef); ok {
+		return block
+	}
+	return LatestBlock
+}
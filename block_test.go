@@ -0,0 +1,99 @@
+package beth_test
+
+import (
+	"context"
+	"math/big"
+	"net/http/httptest"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/republicprotocol/beth-go"
+)
+
+var _ = Describe("block-pinned reads", func() {
+
+	var chain *fakeChain
+	var server *httptest.Server
+	var account beth.Account
+	var ctx context.Context
+	var cancel context.CancelFunc
+
+	hash := common.HexToHash("0x1234")
+	holder := common.HexToAddress("0x0000000000000000000000000000000000000001")
+
+	BeforeEach(func() {
+		chain = newFakeChain(100)
+		chain.balance = big.NewInt(7)
+		server = httptest.NewServer(chain)
+
+		key, err := crypto.GenerateKey()
+		Expect(err).ShouldNot(HaveOccurred())
+		account, err = beth.NewAccount(server.URL, key, beth.NewFixedGasPriceOracle(big.NewInt(1)))
+		Expect(err).ShouldNot(HaveOccurred())
+		ctx, cancel = context.WithTimeout(context.Background(), 5*time.Second)
+	})
+
+	AfterEach(func() {
+		cancel()
+		server.Close()
+	})
+
+	Context("when reading the balance of the account at a block number", func() {
+		It("should read the balance at that block", func() {
+			balance, err := account.BalanceAt(ctx, big.NewInt(42))
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(balance.Int64()).Should(Equal(int64(7)))
+			Expect(chain.balanceBlocks).Should(Equal([]string{"0x2a"}))
+		})
+
+		It("should read the balance at the latest block if the block number is nil", func() {
+			_, err := account.BalanceAt(ctx, nil)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(chain.balanceBlocks).Should(Equal([]string{"latest"}))
+		})
+	})
+
+	Context("when the context carries a block", func() {
+		It("should read balances at the block", func() {
+			client := account.Client()
+			_, err := client.BalanceOf(beth.WithBlock(ctx, beth.FinalizedBlock), holder)
+			Expect(err).ShouldNot(HaveOccurred())
+			_, err = client.BalancesOf(beth.WithBlock(ctx, beth.PendingBlock), []common.Address{holder})
+			Expect(err).ShouldNot(HaveOccurred())
+			_, err = account.BalanceAt(beth.WithBlock(ctx, beth.BlockAtHash(hash)), nil)
+			Expect(err).ShouldNot(HaveOccurred())
+
+			Expect(chain.balanceBlocks).Should(Equal([]string{
+				"finalized",
+				"pending",
+				`{"blockHash":"` + hash.Hex() + `","requireCanonical":true}`,
+			}))
+		})
+
+		It("should read tokens at the block", func() {
+			token, err := account.NewERC20("0x0000000000000000000000000000000000000abc")
+			Expect(err).ShouldNot(HaveOccurred())
+
+			balance, err := token.BalanceOf(beth.WithBlock(ctx, beth.SafeBlock), holder)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(balance.Int64()).Should(Equal(int64(7)))
+			_, err = token.BalancesOf(beth.WithBlock(ctx, beth.BlockAtNumber(big.NewInt(99))), []common.Address{holder})
+			Expect(err).ShouldNot(HaveOccurred())
+
+			Expect(chain.callBlocks).Should(Equal([]string{"safe", "0x63"}))
+		})
+	})
+
+	Context("when formatting a block", func() {
+		It("should return its number, hash or tag", func() {
+			Expect(beth.BlockAtNumber(big.NewInt(42)).String()).Should(Equal("42"))
+			Expect(beth.BlockAtNumber(nil).String()).Should(Equal("latest"))
+			Expect(beth.BlockAtHash(hash).String()).Should(Equal(hash.Hex()))
+			Expect(beth.SafeBlock.String()).Should(Equal("safe"))
+		})
+	})
+})
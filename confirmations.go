@@ -0,0 +1,427 @@
+package beth
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"sync"
+	"time"
+
+	ethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// DefaultConfirmationPollInterval is the interval at which a
+// ConfirmationTracker polls for new blocks when the client cannot subscribe
+// to new heads.
+const DefaultConfirmationPollInterval = 5 * time.Second
+
+// Numbers of blocks with a special meaning when they are passed as the number
+// of blocks to wait for to Transact, Transfer or TrackTransaction.
+const (
+	// WaitForSafe waits until the block of the transaction is at or below the
+	// latest safe block, which is safe from reorgs under honest majority.
+	WaitForSafe = int64(-1)
+
+	// WaitForFinalized waits until the block of the transaction is at or
+	// below the latest finalized block, which cannot be reorged.
+	WaitForFinalized = int64(-2)
+)
+
+// errReceiptNotCanonical is returned when the block of a receipt is not in the
+// canonical chain seen by the client.
+var errReceiptNotCanonical = errors.New("receipt is not in the canonical chain")
+
+// ConfirmationTracker tracks the number of confirmations of mined
+// transactions. It follows the head of the chain using a newHeads subscription
+// when the client is connected over WebSocket or IPC, and falls back to
+// polling at an interval when it is connected over HTTP. All transactions that
+// are tracked at the same time share a single subscription.
+type ConfirmationTracker interface {
+
+	// Track returns a channel of confirmation counts for a transaction mined
+	// in the given block. A count is sent whenever it changes. The channel
+	// is closed once the required number of confirmations is sent, or when
+	// the context is done.
+	Track(ctx context.Context, blockNumber *big.Int, confirmations uint64) <-chan uint64
+
+	// TrackTransaction returns a channel of ConfirmationEvents for a mined
+	// transaction. On every new head, the receipt of the transaction is
+	// checked against the canonical chain, so that a transaction that moves
+	// to another block, or falls out of the chain, is noticed. A transaction
+	// that falls out of the chain is sent again. The transaction is confirmed
+	// once it has waitForBlocks confirmations in the canonical chain, or once
+	// its block is safe or finalized if waitForBlocks is WaitForSafe or
+	// WaitForFinalized. The channel is closed once the transaction is
+	// confirmed, when an event with an error is sent, or when the context is
+	// done.
+	TrackTransaction(ctx context.Context, tx *types.Transaction, receipt *types.Receipt, waitForBlocks int64) <-chan ConfirmationEvent
+}
+
+// ConfirmationStatus is the status of a transaction that is being tracked.
+type ConfirmationStatus uint8
+
+// ConfirmationStatus values.
+const (
+	// Confirming indicates that the number of confirmations of the
+	// transaction changed.
+	Confirming = ConfirmationStatus(iota)
+
+	// Reorged indicates that the transaction was moved to another block by a
+	// reorg.
+	Reorged
+
+	// Dropped indicates that the transaction fell out of the canonical chain
+	// and has been sent again.
+	Dropped
+)
+
+// String implements the fmt.Stringer interface.
+func (status ConfirmationStatus) String() string {
+	switch status {
+	case Confirming:
+		return "confirming"
+	case Reorged:
+		return "reorged"
+	case Dropped:
+		return "dropped"
+	default:
+		return "unknown"
+	}
+}
+
+// ConfirmationEvent describes a change to a transaction that is being
+// tracked.
+type ConfirmationEvent struct {
+	Status ConfirmationStatus
+
+	// Confirmations of the transaction in the canonical chain. It is zero if
+	// the transaction has been dropped.
+	Confirmations uint64
+
+	// Receipt of the transaction in the canonical chain. It is nil if the
+	// transaction has been dropped.
+	Receipt *types.Receipt
+
+	// Confirmed is true if the transaction is confirmed. It is only true for
+	// the last event.
+	Confirmed bool
+
+	// Err is the error that stopped the tracking of the transaction, such as
+	// a dropped transaction that could not be sent again, or a node that does
+	// not support the safe or finalized block. It is only set for the last
+	// event.
+	Err error
+}
+
+type confirmationTracker struct {
+	mu           *sync.Mutex
+	client       *Client
+	pollInterval time.Duration
+
+	head        uint64
+	hasHead     bool
+	subscribers map[chan uint64]struct{}
+	cancel      context.CancelFunc
+}
+
+// NewConfirmationTracker returns a ConfirmationTracker that follows the head
+// of the chain seen by the client. If the client cannot subscribe to new
+// heads, the head is polled at the given interval.
+func NewConfirmationTracker(client Client, pollInterval time.Duration) ConfirmationTracker {
+	return newConfirmationTracker(&client, pollInterval)
+}
+
+// newConfirmationTracker returns a ConfirmationTracker that shares the client,
+// so that it sees the logger, metrics and retry policy of the client when they
+// change.
+func newConfirmationTracker(client *Client, pollInterval time.Duration) *confirmationTracker {
+	if pollInterval <= 0 {
+		pollInterval = DefaultConfirmationPollInterval
+	}
+	return &confirmationTracker{
+		mu:           new(sync.Mutex),
+		client:       client,
+		pollInterval: pollInterval,
+
+		subscribers: map[chan uint64]struct{}{},
+	}
+}
+
+// Track implements the ConfirmationTracker interface.
+func (tracker *confirmationTracker) Track(ctx context.Context, blockNumber *big.Int, confirmations uint64) <-chan uint64 {
+	updates := make(chan uint64)
+	heads := tracker.subscribe()
+
+	go func() {
+		defer close(updates)
+		defer tracker.unsubscribe(heads)
+
+		sent := false
+		last := uint64(0)
+		for {
+			var head uint64
+			select {
+			case <-ctx.Done():
+				return
+			case head = <-heads:
+			}
+
+			// Blocks before the block of the transaction, which can be seen
+			// by nodes that are behind, do not confirm it
+			if head < blockNumber.Uint64() {
+				continue
+			}
+			count := head - blockNumber.Uint64()
+			if sent && count == last {
+				continue
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case updates <- count:
+			}
+			sent, last = true, count
+			if count >= confirmations {
+				return
+			}
+		}
+	}()
+	return updates
+}
+
+// TrackTransaction implements the ConfirmationTracker interface.
+func (tracker *confirmationTracker) TrackTransaction(ctx context.Context, tx *types.Transaction, receipt *types.Receipt, waitForBlocks int64) <-chan ConfirmationEvent {
+	events := make(chan ConfirmationEvent)
+	heads := tracker.subscribe()
+
+	go func() {
+		defer close(events)
+		defer tracker.unsubscribe(heads)
+
+		send := func(event ConfirmationEvent) bool {
+			select {
+			case <-ctx.Done():
+				return false
+			case events <- event:
+				return true
+			}
+		}
+
+		blockHash := receipt.BlockHash
+		dropped := false
+		sent := false
+		last := uint64(0)
+		for {
+			var head uint64
+			select {
+			case <-ctx.Done():
+				return
+			case head = <-heads:
+			}
+
+			current, err := tracker.canonicalReceipt(ctx, tx.Hash())
+			if errors.Is(err, ethereum.NotFound) {
+				if dropped {
+					continue
+				}
+
+				// The transaction fell out of the canonical chain, so send it
+				// again to get it mined in the new chain
+				dropped = true
+				tracker.client.log().Warn("transaction dropped from the chain, sending it again", "hash", tx.Hash(), "nonce", tx.Nonce(), "block", blockHash)
+				if err := tracker.client.Backend().SendTransaction(ctx, tx); err != nil {
+					if err = ClassifyError(err); !errors.Is(err, ErrAlreadyKnown) {
+						send(ConfirmationEvent{Status: Dropped, Err: err})
+						return
+					}
+				}
+				if !send(ConfirmationEvent{Status: Dropped}) {
+					return
+				}
+				continue
+			}
+			if err != nil {
+				// The receipt cannot be checked against this head, so wait
+				// for the next head
+				tracker.client.log().Debug("cannot check transaction receipt", "hash", tx.Hash(), "err", err)
+				continue
+			}
+
+			status := Confirming
+			if dropped || current.BlockHash != blockHash {
+				status = Reorged
+				tracker.client.log().Warn("transaction reorged", "hash", tx.Hash(), "fromBlock", blockHash, "toBlock", current.BlockHash, "number", current.BlockNumber)
+				blockHash = current.BlockHash
+				dropped = false
+			}
+
+			// Heads before the block of the transaction, which can be seen by
+			// nodes that are behind, do not confirm it
+			count := uint64(0)
+			if head > current.BlockNumber.Uint64() {
+				count = head - current.BlockNumber.Uint64()
+			}
+			confirmed, err := tracker.confirmed(ctx, current, count, waitForBlocks)
+			if status == Confirming && sent && count == last && !confirmed && err == nil {
+				continue
+			}
+			if !send(ConfirmationEvent{Status: status, Confirmations: count, Receipt: current, Confirmed: confirmed, Err: err}) {
+				return
+			}
+			sent, last = true, count
+			if confirmed || err != nil {
+				return
+			}
+		}
+	}()
+	return events
+}
+
+// confirmed returns true if a transaction with the given receipt and number of
+// confirmations has waited for enough blocks.
+func (tracker *confirmationTracker) confirmed(ctx context.Context, receipt *types.Receipt, confirmations uint64, waitForBlocks int64) (bool, error) {
+	var blockNumber *big.Int
+	var err error
+	switch waitForBlocks {
+	case WaitForSafe:
+		blockNumber, err = tracker.client.SafeBlockNumber(ctx)
+	case WaitForFinalized:
+		blockNumber, err = tracker.client.FinalizedBlockNumber(ctx)
+	default:
+		return waitForBlocks <= 0 || confirmations >= uint64(waitForBlocks), nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return receipt.BlockNumber.Cmp(blockNumber) <= 0, nil
+}
+
+// canonicalReceipt returns the receipt of a transaction, if its block is in
+// the canonical chain. It returns ethereum.NotFound if the transaction is not
+// in the canonical chain.
+func (tracker *confirmationTracker) canonicalReceipt(ctx context.Context, hash common.Hash) (*types.Receipt, error) {
+	receipt, err := tracker.client.Backend().TransactionReceipt(ctx, hash)
+	if err != nil {
+		return nil, err
+	}
+	header, err := tracker.client.Backend().HeaderByNumber(ctx, receipt.BlockNumber)
+	if errors.Is(err, ethereum.NotFound) {
+		// The transaction is not dropped just because the node has not yet
+		// seen the header of its block
+		return nil, errReceiptNotCanonical
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Nodes can briefly return receipts of blocks that are no longer in the
+	// canonical chain while they process a reorg
+	if header.Hash() != receipt.BlockHash {
+		return nil, errReceiptNotCanonical
+	}
+	return receipt, nil
+}
+
+// subscribe returns a channel that receives the latest head of the chain. The
+// channel holds at most one head, so a slow subscriber only misses heads that
+// have already been superseded. The first subscriber starts following the
+// head of the chain.
+func (tracker *confirmationTracker) subscribe() chan uint64 {
+	tracker.mu.Lock()
+	defer tracker.mu.Unlock()
+
+	heads := make(chan uint64, 1)
+	if tracker.hasHead {
+		heads <- tracker.head
+	}
+	tracker.subscribers[heads] = struct{}{}
+	if tracker.cancel == nil {
+		ctx, cancel := context.WithCancel(context.Background())
+		tracker.cancel = cancel
+		go tracker.follow(ctx)
+	}
+	return heads
+}
+
+// unsubscribe removes a channel returned by subscribe. The last subscriber
+// stops following the head of the chain.
+func (tracker *confirmationTracker) unsubscribe(heads chan uint64) {
+	tracker.mu.Lock()
+	defer tracker.mu.Unlock()
+
+	delete(tracker.subscribers, heads)
+	if len(tracker.subscribers) == 0 && tracker.cancel != nil {
+		tracker.cancel()
+		tracker.cancel = nil
+		tracker.hasHead = false
+	}
+}
+
+// publish sends a new head of the chain to all subscribers, replacing any
+// head that they have not yet received.
+func (tracker *confirmationTracker) publish(ctx context.Context, head uint64) {
+	tracker.mu.Lock()
+	defer tracker.mu.Unlock()
+
+	// The tracker may have stopped while the head was being fetched
+	if ctx.Err() != nil {
+		return
+	}
+	tracker.head, tracker.hasHead = head, true
+	for heads := range tracker.subscribers {
+		select {
+		case <-heads:
+		default:
+		}
+		heads <- head
+	}
+}
+
+// follow publishes the head of the chain until the context is done. It uses a
+// newHeads subscription if possible, and polls the head of the chain
+// otherwise.
+func (tracker *confirmationTracker) follow(ctx context.Context) {
+	poll := func() {
+		head, err := tracker.client.Backend().BlockNumber(ctx)
+		if err != nil {
+			tracker.client.log().Debug("cannot poll block number", "err", err)
+			return
+		}
+		tracker.publish(ctx, head)
+	}
+
+	headers := make(chan *types.Header)
+	sub, err := tracker.client.SubscribeNewHead(ctx, headers)
+	if err != nil {
+		tracker.client.log().Debug("cannot subscribe to new heads, polling instead", "err", err, "interval", tracker.pollInterval)
+		sub = nil
+	}
+	poll()
+
+	for {
+		if sub == nil {
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(tracker.pollInterval):
+				poll()
+			}
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			sub.Unsubscribe()
+			return
+		case header := <-headers:
+			tracker.publish(ctx, header.Number.Uint64())
+		case err := <-sub.Err():
+			// The subscription failed, so poll for the rest of the time that
+			// the tracker is following the chain
+			tracker.client.log().Warn("new heads subscription failed, polling instead", "err", err, "interval", tracker.pollInterval)
+			sub = nil
+		}
+	}
+}
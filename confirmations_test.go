@@ -0,0 +1,235 @@
+package beth_test
+
+import (
+	"context"
+	"math/big"
+	"net/http/httptest"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/republicprotocol/beth-go"
+)
+
+var _ = Describe("confirmation tracker", func() {
+
+	var chain *fakeChain
+	var server *httptest.Server
+	var tracker beth.ConfirmationTracker
+
+	BeforeEach(func() {
+		chain = newFakeChain(100)
+		server = httptest.NewServer(chain)
+		client, err := beth.Connect(server.URL)
+		Expect(err).ShouldNot(HaveOccurred())
+		tracker = beth.NewConfirmationTracker(client, 10*time.Millisecond)
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	// track collects the events of a transaction mined in the given block.
+	track := func(tx *types.Transaction, number uint64, waitForBlocks int64) []beth.ConfirmationEvent {
+		chain.mu.Lock()
+		chain.mine(tx, number)
+		receipt := chain.receipts[tx.Hash()]
+		chain.mu.Unlock()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		events := []beth.ConfirmationEvent{}
+		for event := range tracker.TrackTransaction(ctx, tx, receipt, waitForBlocks) {
+			events = append(events, event)
+		}
+		Expect(ctx.Err()).ShouldNot(HaveOccurred())
+		return events
+	}
+
+	Context("when the client cannot subscribe to new heads", func() {
+		It("should poll for confirmations until the required number is reached", func() {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			confirmations := []uint64{}
+			for count := range tracker.Track(ctx, big.NewInt(100), 3) {
+				confirmations = append(confirmations, count)
+			}
+			Expect(confirmations).Should(Equal([]uint64{0, 1, 2, 3}))
+		})
+
+		It("should share the head between tracked transactions", func() {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			first := tracker.Track(ctx, big.NewInt(98), 4)
+			second := tracker.Track(ctx, big.NewInt(100), 2)
+			var lastFirst, lastSecond uint64
+			for count := range first {
+				lastFirst = count
+			}
+			for count := range second {
+				lastSecond = count
+			}
+			Expect(lastFirst).Should(Equal(uint64(4)))
+			Expect(lastSecond).Should(Equal(uint64(2)))
+		})
+
+		It("should close the channel when the context is done", func() {
+			ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+			defer cancel()
+
+			for range tracker.Track(ctx, big.NewInt(1000), 1) {
+			}
+			Expect(ctx.Err()).Should(HaveOccurred())
+		})
+	})
+
+	Context("when the transaction stays in the canonical chain", func() {
+		It("should report its confirmations", func() {
+			events := track(newSignedTx(), 100, 3)
+			Expect(events).Should(HaveLen(4))
+			for i, event := range events {
+				Expect(event.Status).Should(Equal(beth.Confirming))
+				Expect(event.Confirmations).Should(Equal(uint64(i)))
+				Expect(event.Confirmed).Should(Equal(i == len(events)-1))
+			}
+		})
+	})
+
+	Context("when waiting for the block of the transaction to be finalized", func() {
+		It("should confirm the transaction once its block is finalized", func() {
+			chain.finalizedLag = 3
+
+			events := track(newSignedTx(), 100, beth.WaitForFinalized)
+			last := events[len(events)-1]
+			Expect(last.Confirmed).Should(BeTrue())
+			Expect(last.Err).ShouldNot(HaveOccurred())
+			Expect(last.Confirmations).Should(Equal(uint64(3)))
+		})
+
+		It("should return an error if the node has no finalized block", func() {
+			events := track(newSignedTx(), 100, beth.WaitForFinalized)
+			Expect(events).Should(HaveLen(1))
+			Expect(events[0].Confirmed).Should(BeFalse())
+			Expect(events[0].Err).Should(MatchError(ContainSubstring("finalized block not found")))
+		})
+	})
+
+	Context("when waiting for the block of the transaction to be safe", func() {
+		It("should confirm the transaction once its block is safe", func() {
+			chain.finalizedLag = 1
+
+			events := track(newSignedTx(), 100, beth.WaitForSafe)
+			last := events[len(events)-1]
+			Expect(last.Confirmed).Should(BeTrue())
+			Expect(last.Confirmations).Should(Equal(uint64(1)))
+		})
+	})
+
+	Context("when the transaction is moved to another block by a reorg", func() {
+		It("should report the reorg and confirm the new block", func() {
+			tx := newSignedTx()
+			chain.onHead = func(chain *fakeChain, head uint64) {
+				if head == 102 {
+					chain.forks[100], chain.forks[101] = "b", "b"
+					chain.mine(tx, 101)
+				}
+			}
+
+			events := track(tx, 100, 3)
+			reorgs := 0
+			for _, event := range events {
+				if event.Status == beth.Reorged {
+					reorgs++
+					Expect(event.Receipt.BlockNumber.Uint64()).Should(Equal(uint64(101)))
+				}
+			}
+			Expect(reorgs).Should(Equal(1))
+
+			last := events[len(events)-1]
+			Expect(last.Confirmations).Should(Equal(uint64(3)))
+			Expect(last.Receipt.BlockNumber.Uint64()).Should(Equal(uint64(101)))
+			Expect(last.Receipt.BlockHash).Should(Equal(chain.header(101).Hash()))
+		})
+	})
+
+	Context("when the tracker belongs to an account", func() {
+		It("should send its events to the logger of the account", func() {
+			key, err := crypto.GenerateKey()
+			Expect(err).ShouldNot(HaveOccurred())
+			account, err := beth.NewAccount(server.URL, key, beth.NewFixedGasPriceOracle(big.NewInt(10e9)))
+			Expect(err).ShouldNot(HaveOccurred())
+			logger := newRecordingLogger()
+			account.SetLogger(logger)
+			tracker = account.ConfirmationTracker()
+
+			// The block of the transaction is replaced before it is tracked
+			tx := newSignedTx()
+			chain.mu.Lock()
+			chain.mine(tx, 100)
+			receipt := chain.receipts[tx.Hash()]
+			chain.forks[100] = "b"
+			chain.mine(tx, 100)
+			chain.mu.Unlock()
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			events := []beth.ConfirmationEvent{}
+			for event := range tracker.TrackTransaction(ctx, tx, receipt, 0) {
+				events = append(events, event)
+			}
+			Expect(events).Should(HaveLen(1))
+			Expect(events[0].Status).Should(Equal(beth.Reorged))
+			Expect(logger.messages("warn")).Should(ContainElement("transaction reorged"))
+		})
+	})
+
+	Context("when the transaction falls out of the canonical chain", func() {
+		It("should send it again and confirm its new block", func() {
+			tx := newSignedTx()
+			chain.onHead = func(chain *fakeChain, head uint64) {
+				switch head {
+				case 102:
+					delete(chain.receipts, tx.Hash())
+				case 104:
+					chain.mine(tx, 104)
+				}
+			}
+
+			events := track(tx, 100, 2)
+			statuses := []beth.ConfirmationStatus{}
+			for _, event := range events {
+				if len(statuses) == 0 || statuses[len(statuses)-1] != event.Status {
+					statuses = append(statuses, event.Status)
+				}
+			}
+			Expect(statuses).Should(Equal([]beth.ConfirmationStatus{beth.Confirming, beth.Dropped, beth.Reorged, beth.Confirming}))
+			Expect(events[len(events)-1].Confirmations).Should(Equal(uint64(2)))
+			Expect(events[len(events)-1].Receipt.BlockNumber.Uint64()).Should(Equal(uint64(104)))
+
+			chain.mu.Lock()
+			defer chain.mu.Unlock()
+			Expect(chain.sent).Should(Equal([]common.Hash{tx.Hash()}))
+		})
+
+		It("should return an error if it cannot be sent again", func() {
+			tx := newSignedTx()
+			chain.sendErr = "nonce too low"
+			chain.onHead = func(chain *fakeChain, head uint64) {
+				if head == 102 {
+					delete(chain.receipts, tx.Hash())
+				}
+			}
+
+			events := track(tx, 100, 5)
+			last := events[len(events)-1]
+			Expect(last.Status).Should(Equal(beth.Dropped))
+			Expect(last.Err).Should(MatchError(beth.ErrNonceTooLow))
+		})
+	})
+})
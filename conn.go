@@ -1,20 +1,20 @@
 package beth
 
 import (
-	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
-	"fmt"
-	"io/ioutil"
 	"math/big"
 	"net/http"
 	"time"
 
+	ethereum "github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
 )
 
 // ErrCannotConvertToBigInt is returned when string cannot be parsed into a
@@ -23,18 +23,33 @@ var ErrCannotConvertToBigInt = errors.New("cannot convert hex string to int: inv
 
 // Client will have a connection to an ethereum client (specified by the url)
 type Client struct {
-	ethClient *ethclient.Client
+	backend   Backend
+	rpcClient *rpc.Client
 	addrBook  AddressBook
 	url       string
+	headers   http.Header
+	logger    Logger
+	metrics   *Metrics
+
+	retryPolicy *RetryPolicy
+	batchSize   int
+
+	// httpClient and endpoints are only set if the client is connected to
+	// several endpoints, in which case requests fail over between them
+	httpClient *http.Client
+	endpoints  *endpointPool
 }
 
-// Connect to an infura network (Supported networks: mainnet and kovan).
+// Connect to an ethereum node. The URL can be an HTTP or WebSocket URL, or the
+// path of an IPC socket. Subscriptions are only available over WebSocket and
+// IPC.
 func Connect(url string) (Client, error) {
 
-	ethClient, err := ethclient.Dial(url)
+	rpcClient, err := rpc.Dial(url)
 	if err != nil {
 		return Client{}, err
 	}
+	ethClient := ethclient.NewClient(rpcClient)
 
 	netID, err := ethClient.NetworkID(context.Background())
 	if err != nil {
@@ -42,12 +57,69 @@ func Connect(url string) (Client, error) {
 	}
 
 	return Client{
-		ethClient: ethClient,
+		backend:   ethClient,
+		rpcClient: rpcClient,
 		addrBook:  DefaultAddressBook(netID.Int64()),
 		url:       url,
+		headers:   http.Header{},
+		logger:    NopLogger(),
 	}, nil
 }
 
+// SetLogger sets the Logger that receives the events of the client. Setting a
+// nil logger discards all events.
+func (client *Client) SetLogger(logger Logger) {
+	if logger == nil {
+		logger = NopLogger()
+	}
+	client.logger = logger
+	if client.endpoints != nil {
+		client.endpoints.setLogger(logger)
+	}
+}
+
+// SetMetrics sets the Metrics that collect measurements of the client. Setting
+// nil metrics discards all measurements.
+func (client *Client) SetMetrics(metrics *Metrics) {
+	client.metrics = metrics
+}
+
+// SetRetryPolicy sets the RetryPolicy used by the reads of the client. Setting
+// a nil policy uses the DefaultRetryPolicy. The policy can be overridden for a
+// single call using WithRetryPolicy.
+func (client *Client) SetRetryPolicy(policy *RetryPolicy) {
+	client.retryPolicy = policy
+}
+
+// Endpoints returns the status of every endpoint of a client connected using
+// ConnectEndpoints. It returns nil if the client has a single endpoint.
+func (client *Client) Endpoints() []EndpointStatus {
+	if client.endpoints == nil {
+		return nil
+	}
+	return client.endpoints.status()
+}
+
+// Close the connection of the client, and stop the health checks of its
+// endpoints.
+func (client *Client) Close() {
+	if client.endpoints != nil {
+		client.endpoints.close()
+	}
+	if closer, ok := client.backend.(interface{ Close() }); ok {
+		closer.Close()
+	}
+}
+
+// log returns the Logger of the client, which discards all events if the
+// client has no logger.
+func (client *Client) log() Logger {
+	if client.logger == nil {
+		return NopLogger()
+	}
+	return client.logger
+}
+
 // WriteAddress to the address book, overwrite if already exists
 func (client *Client) WriteAddress(key string, address common.Address) {
 	client.addrBook[key] = address
@@ -65,17 +137,19 @@ func (client *Client) ReadAddress(key string) (common.Address, error) {
 // WaitMined waits for tx to be mined on the blockchain.
 // It stops waiting when the context is canceled.
 func (client *Client) WaitMined(ctx context.Context, tx *types.Transaction) (*types.Receipt, error) {
-	return bind.WaitMined(ctx, client.ethClient, tx)
+	return bind.WaitMined(ctx, client.backend, tx)
 }
 
 // Get will perform a read-only transaction on the ethereum blockchain.
+// It is retried according to the RetryPolicy of the client, or the policy
+// carried by the context.
 func (client *Client) Get(ctx context.Context, f func() error) (err error) {
 
-	sleepDurationMs := time.Duration(1000)
+	policy := retryPolicy(ctx, client.retryPolicy)
 
-	// Keep retrying until the read-only transaction succeeds or until context
-	// times out
-	for {
+	// Keep retrying until the read-only transaction succeeds, until the policy
+	// stops retrying or until context times out
+	for attempt := 1; ; attempt++ {
 		select {
 		case <-ctx.Done():
 			if err == nil {
@@ -85,9 +159,15 @@ func (client *Client) Get(ctx context.Context, f func() error) (err error) {
 		default:
 		}
 
+		client.metrics.getAttempt(attempt)
 		if err = f(); err == nil {
 			return
 		}
+		if !policy.retryable(err) || policy.exhausted(attempt) {
+			return
+		}
+		delay := policy.delay(attempt)
+		client.log().Debug("retrying read", "err", err, "attempt", attempt, "delay", delay)
 
 		// If transaction errors, wait for sometime before retrying
 		select {
@@ -96,186 +176,175 @@ func (client *Client) Get(ctx context.Context, f func() error) (err error) {
 				return ctx.Err()
 			}
 			return
-		case <-time.After(sleepDurationMs * time.Millisecond):
-		}
-
-		// Increase delay for next round but saturate at 30s
-		sleepDurationMs = time.Duration(float64(sleepDurationMs) * 1.6)
-		if sleepDurationMs > 30000 {
-			sleepDurationMs = 30000
+		case <-time.After(delay):
 		}
 	}
 }
 
-// BalanceOf returns the ethereum balance of the addr passed.
+// BalanceOf returns the ethereum balance of the addr passed, at the block
+// carried by the context.
 func (client *Client) BalanceOf(ctx context.Context, addr common.Address) (val *big.Int, err error) {
-	err = client.Get(ctx, func() (err error) {
-		val, err = client.ethClient.BalanceAt(ctx, addr, nil)
-		return
+	block := blockRef(ctx)
+	if !client.hasEndpoint() {
+		if block.tag == PendingBlock.tag {
+			backend, ok := client.backend.(pendingBalanceReader)
+			if !ok {
+				return nil, ErrBlockNotSupported
+			}
+			err = client.Get(ctx, func() (err error) {
+				val, err = backend.PendingBalanceAt(ctx, addr)
+				return
+			})
+			return val, err
+		}
+		number, err := client.backendBlock(ctx, block)
+		if err != nil {
+			return nil, err
+		}
+		err = client.Get(ctx, func() (err error) {
+			val, err = client.backend.BalanceAt(ctx, addr, number)
+			return
+		})
+		return val, err
+	}
+	err = client.Get(ctx, func() error {
+		var balance hexutil.Big
+		if err := client.Call(ctx, &balance, "eth_getBalance", addr, block.arg()); err != nil {
+			return err
+		}
+		val = balance.ToInt()
+		return nil
 	})
 	return
 }
 
-// EthClient returns the ethereum client connection.
-func (client *Client) EthClient() *ethclient.Client {
-	return client.ethClient
-}
-
-// TxBlockNumber retrieves tx's block number using the tx hash.
-func (client *Client) TxBlockNumber(ctx context.Context, hash string) (*big.Int, error) {
-
-	type Result struct {
-		BlockNumber string `json:"blockNumber,omitempty"`
+// callContract executes an `eth_call` to the contract at the block carried by
+// the context, and returns its return data. It returns an *ErrReverted if the
+// call reverts.
+func (client *Client) callContract(ctx context.Context, to common.Address, data []byte) ([]byte, error) {
+	if !client.hasEndpoint() {
+		msg := ethereum.CallMsg{To: &to, Data: data}
+		if blockRef(ctx).tag == PendingBlock.tag {
+			backend, ok := client.backend.(bind.PendingContractCaller)
+			if !ok {
+				return nil, ErrBlockNotSupported
+			}
+			returnData, err := backend.PendingCallContract(ctx, msg)
+			if err != nil {
+				return nil, callErr(err)
+			}
+			return returnData, nil
+		}
+		number, err := client.backendBlock(ctx, blockRef(ctx))
+		if err != nil {
+			return nil, err
+		}
+		returnData, err := client.backend.CallContract(ctx, msg, number)
+		if err != nil {
+			return nil, callErr(err)
+		}
+		return returnData, nil
 	}
-	type JSONResponse struct {
-		Result Result `json:"result,omitempty"`
+	var returnData hexutil.Bytes
+	msg := map[string]interface{}{"to": to, "data": hexutil.Bytes(data)}
+	if err := client.Call(ctx, &returnData, "eth_call", msg, blockRef(ctx).arg()); err != nil {
+		return nil, callErr(err)
 	}
-	var data JSONResponse
+	return returnData, nil
+}
 
-	var jsonStr = `{"jsonrpc":"2.0","method":"eth_getTransactionByHash",` +
-		`"params":["` + hash + `"],"id":1}`
+// SubscribeNewHead subscribes to the headers of new blocks. It returns
+// rpc.ErrNotificationsUnsupported if the transport of the client does not
+// support subscriptions, such as HTTP.
+func (client *Client) SubscribeNewHead(ctx context.Context, ch chan<- *types.Header) (ethereum.Subscription, error) {
+	return client.backend.SubscribeNewHead(ctx, ch)
+}
 
-	// Keep retrying until a block number is returned or until context times out
-	for {
-		select {
-		case <-ctx.Done():
-			return nil, ctx.Err()
-		default:
-		}
+// SubscribeLogs subscribes to the logs that match the query. It returns
+// rpc.ErrNotificationsUnsupported if the transport of the client does not
+// support subscriptions, such as HTTP.
+func (client *Client) SubscribeLogs(ctx context.Context, query ethereum.FilterQuery, ch chan<- types.Log) (ethereum.Subscription, error) {
+	return client.backend.SubscribeFilterLogs(ctx, query, ch)
+}
 
-		response, err := sendInfuraRequest(ctx, client.url, jsonStr)
-		if err != nil {
-			continue
-		}
-		err = json.Unmarshal(response, &data)
+// EthClient returns the ethereum client connection. It returns nil if the
+// client reads from a Backend that is not an *ethclient.Client.
+func (client *Client) EthClient() *ethclient.Client {
+	ethClient, _ := client.backend.(*ethclient.Client)
+	return ethClient
+}
 
-		if err != nil || data.Result == (Result{}) || data.Result.BlockNumber == "" {
-			select {
-			case <-ctx.Done():
-				return nil, ctx.Err()
-			case <-time.After(5 * time.Millisecond):
-			}
-			continue
-		}
-		break
+// TxBlockNumber retrieves tx's block number using the tx hash. It waits until
+// the transaction is mined, or until the retry policy stops retrying.
+func (client *Client) TxBlockNumber(ctx context.Context, hash string) (*big.Int, error) {
+	if !client.hasEndpoint() {
+		return client.backendTxBlockNumber(ctx, common.HexToHash(hash))
 	}
-
-	return hexToBigInt(data.Result.BlockNumber)
+	return client.blockNumber(ctx, "blockNumber", "eth_getTransactionByHash", hash)
 }
 
 // CurrentBlockNumber will retrieve the current block that is confirmed by
 // infura.
 func (client *Client) CurrentBlockNumber(ctx context.Context) (*big.Int, error) {
+	return client.blockNumberByTag(ctx, "latest")
+}
 
-	type Result struct {
-		Number string `json:"number,omitempty"`
-	}
-	type JSONResponse struct {
-		Result Result `json:"result,omitempty"`
-	}
-	var data JSONResponse
-
-	var jsonStr = `{"jsonrpc":"2.0","method":"eth_getBlockByNumber",` +
-		`"params":["latest", false],"id":1}`
-
-	// Keep retrying until a block number is returned or until context times out
-	for {
-		select {
-		case <-ctx.Done():
-			return nil, ctx.Err()
-		default:
-		}
-
-		response, err := sendInfuraRequest(ctx, client.url, jsonStr)
-		if err != nil {
-			continue
-		}
-		err = json.Unmarshal(response, &data)
-
-		if err != nil || data.Result == (Result{}) || data.Result.Number == "" {
-			select {
-			case <-ctx.Done():
-				return nil, ctx.Err()
-			case <-time.After(5 * time.Millisecond):
-			}
-			continue
-		}
-		break
-	}
+// SafeBlockNumber will retrieve the latest block that is safe from reorgs
+// under honest majority, as seen by the consensus client of the node.
+func (client *Client) SafeBlockNumber(ctx context.Context) (*big.Int, error) {
+	return client.blockNumberByTag(ctx, "safe")
+}
 
-	return hexToBigInt(data.Result.Number)
+// FinalizedBlockNumber will retrieve the latest block that has been finalized
+// by the consensus client of the node, and cannot be reorged.
+func (client *Client) FinalizedBlockNumber(ctx context.Context) (*big.Int, error) {
+	return client.blockNumberByTag(ctx, "finalized")
 }
 
-// hexToBigInt will convert a hex value in string format to the corresponding
-// big.Int value. For example : "0xFD6CE" will return big.Int(1038030).
-func hexToBigInt(hex string) (*big.Int, error) {
-	bigInt := big.NewInt(0)
-	bigIntStr := hex[2:]
-	bigInt, ok := bigInt.SetString(bigIntStr, 16)
-	if !ok {
-		return bigInt, ErrCannotConvertToBigInt
+// blockNumberByTag will retrieve the number of the block with the given tag.
+// An error is returned without retrying if the node does not support the tag.
+func (client *Client) blockNumberByTag(ctx context.Context, tag string) (*big.Int, error) {
+	if !client.hasEndpoint() {
+		return client.backendBlockNumber(ctx, tag)
 	}
-	return bigInt, nil
+	return client.blockNumber(ctx, "number", "eth_getBlockByNumber", tag, false)
 }
 
-// sendInfuraRequest will send a request to infura and return the unmarshalled data
-// back to the caller. It will retry until a valid response is returned, or
-// until the context times out.
-func sendInfuraRequest(ctx context.Context, url string, request string) (body []byte, err error) {
+// blockNumber calls a JSON-RPC method that returns an object with a block
+// number in the given field. It keeps retrying until the block number is
+// known, until the retry policy stops retrying, or until the context times
+// out. Errors returned by the endpoint are returned without retrying.
+func (client *Client) blockNumber(ctx context.Context, field string, method string, params ...interface{}) (*big.Int, error) {
 
-	sleepDurationMs := time.Duration(1000)
+	policy := retryPolicy(ctx, client.retryPolicy)
 
-	// Retry until a valid response is returned or until context times out
-	for {
-		select {
-		case <-ctx.Done():
-			return nil, ctx.Err()
-		default:
+	for attempt := 1; ; attempt++ {
+		var result map[string]json.RawMessage
+		err := client.Call(ctx, &result, method, params...)
+		var rpcErr *RPCError
+		if errors.As(err, &rpcErr) {
+			return nil, err
 		}
-
-		if body, err = func() ([]byte, error) {
-			// Create a new http POST request
-			req, err := http.NewRequest("POST", url, bytes.NewBuffer([]byte(request)))
-			if err != nil {
-				return nil, err
-			}
-
-			// Send http POST request
-			client := &http.Client{}
-			resp, err := client.Do(req)
-			if err != nil {
-				return nil, err
+		if err == nil {
+			if number, ok := result[field]; ok && string(number) != "null" {
+				var blockNumber hexutil.Big
+				if err := json.Unmarshal(number, &blockNumber); err != nil {
+					return nil, ErrCannotConvertToBigInt
+				}
+				return blockNumber.ToInt(), nil
 			}
 
-			// Decode response body
-			return func() ([]byte, error) {
-				defer resp.Body.Close()
-
-				// Check status
-				if resp.StatusCode != http.StatusOK {
-					return nil, fmt.Errorf("unexpected status %v", resp.StatusCode)
-				}
-				// Check body
-				if resp.Body != nil {
-					return ioutil.ReadAll(resp.Body)
-				}
-				return nil, fmt.Errorf("response body is nil")
-			}()
-		}(); err == nil {
-			break
+			// The object, or its block number, is not yet known
+			err = ethereum.NotFound
 		}
+		if !policy.retryable(err) || policy.exhausted(attempt) {
+			return nil, err
+		}
+
 		select {
 		case <-ctx.Done():
 			return nil, ctx.Err()
-		case <-time.After(sleepDurationMs * time.Millisecond):
-
-		}
-
-		// Increase delay for next round but saturate at 30s
-		sleepDurationMs = time.Duration(float64(sleepDurationMs) * 1.6)
-		if sleepDurationMs > 30000 {
-			sleepDurationMs = 30000
+		case <-time.After(policy.delay(attempt)):
 		}
 	}
-	return
 }
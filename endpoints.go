@@ -0,0 +1,487 @@
+package beth
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// ErrNoEndpoints is returned when a Client is connected to an empty list of
+// endpoints, or when a JSON-RPC request is sent by a Client that only has a
+// Backend.
+var ErrNoEndpoints = errors.New("no endpoints")
+
+// SelectionStrategy determines the order in which the endpoints of a Client
+// are tried.
+type SelectionStrategy uint8
+
+// SelectionStrategy values.
+const (
+	// PriorityStrategy tries the endpoints in the order in which they were
+	// given.
+	PriorityStrategy = SelectionStrategy(iota)
+
+	// RoundRobinStrategy starts every request at the endpoint after the one
+	// that started the previous request.
+	RoundRobinStrategy
+
+	// LowestLatencyStrategy tries the endpoint with the lowest latency
+	// first.
+	LowestLatencyStrategy
+)
+
+// EndpointOptions configure how a Client chooses between its endpoints.
+type EndpointOptions struct {
+
+	// Strategy used to choose between healthy endpoints.
+	Strategy SelectionStrategy
+
+	// HealthCheckInterval is the interval at which the block number of every
+	// endpoint is checked. A zero interval disables health checks, in which
+	// case endpoints are only ejected when their requests fail.
+	HealthCheckInterval time.Duration
+
+	// MaxBlockLag is the number of blocks that an endpoint can be behind the
+	// highest block seen by any endpoint before it is ejected.
+	MaxBlockLag uint64
+
+	// MaxFailures is the number of consecutive failed requests after which
+	// an endpoint is ejected until it passes a health check. Values below 1
+	// eject an endpoint after its first failure.
+	MaxFailures int
+}
+
+// DefaultEndpointOptions try the endpoints in order, checking their health
+// every 15 seconds and ejecting endpoints that are more than 3 blocks behind,
+// or that fail 3 requests in a row.
+func DefaultEndpointOptions() EndpointOptions {
+	return EndpointOptions{
+		Strategy:            PriorityStrategy,
+		HealthCheckInterval: 15 * time.Second,
+		MaxBlockLag:         3,
+		MaxFailures:         3,
+	}
+}
+
+// EndpointStatus describes the health of an endpoint of a Client.
+type EndpointStatus struct {
+	URL         string
+	Healthy     bool
+	BlockNumber uint64
+	Latency     time.Duration
+	Err         error
+}
+
+// endpoint is a single JSON-RPC endpoint and its health.
+type endpoint struct {
+	url      *url.URL
+	healthy  bool
+	failures int
+	latency  time.Duration
+	block    uint64
+	err      error
+}
+
+// endpointPool is an http.RoundTripper that sends every JSON-RPC request to
+// the best endpoint, and fails over to the other endpoints if it fails.
+type endpointPool struct {
+	mu        *sync.Mutex
+	options   EndpointOptions
+	transport http.RoundTripper
+	endpoints []*endpoint
+	next      int
+	headers   http.Header
+	logger    Logger
+	cancel    context.CancelFunc
+
+	defaultQuorum *Quorum
+}
+
+// ConnectEndpoints connects to several HTTP(S) JSON-RPC endpoints, for example
+// of different providers, that serve the same network. Every request, made
+// through the ethclient or otherwise, is sent to the best endpoint according
+// to the options, and transparently fails over to the other endpoints. Health
+// checks run in the background until the Client is closed.
+func ConnectEndpoints(urls []string, options EndpointOptions) (Client, error) {
+	if len(urls) == 0 {
+		return Client{}, ErrNoEndpoints
+	}
+
+	pool := &endpointPool{
+		mu:        new(sync.Mutex),
+		options:   options,
+		transport: http.DefaultTransport,
+		endpoints: make([]*endpoint, 0, len(urls)),
+		headers:   http.Header{},
+		logger:    NopLogger(),
+	}
+	for _, rawURL := range urls {
+		endpointURL, err := url.Parse(rawURL)
+		if err != nil {
+			return Client{}, err
+		}
+		if endpointURL.Scheme != "http" && endpointURL.Scheme != "https" {
+			return Client{}, fmt.Errorf("unsupported endpoint scheme %q: only http and https endpoints can fail over", endpointURL.Scheme)
+		}
+		pool.endpoints = append(pool.endpoints, &endpoint{url: endpointURL, healthy: true})
+	}
+
+	httpClient := &http.Client{Transport: pool}
+	rpcClient, err := rpc.DialHTTPWithClient(urls[0], httpClient)
+	if err != nil {
+		return Client{}, err
+	}
+	ethClient := ethclient.NewClient(rpcClient)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	netID, err := ethClient.NetworkID(ctx)
+	if err != nil {
+		ethClient.Close()
+		return Client{}, err
+	}
+
+	if options.HealthCheckInterval > 0 {
+		ctx, cancel := context.WithCancel(context.Background())
+		pool.cancel = cancel
+		go pool.checkHealth(ctx)
+	}
+
+	return Client{
+		backend:    ethClient,
+		rpcClient:  rpcClient,
+		addrBook:   DefaultAddressBook(netID.Int64()),
+		url:        urls[0],
+		headers:    http.Header{},
+		logger:     NopLogger(),
+		httpClient: httpClient,
+		endpoints:  pool,
+	}, nil
+}
+
+// RoundTrip implements the http.RoundTripper interface. The request is sent to
+// the endpoints in order until one of them responds without a server error.
+// If the request is a read and a Quorum applies to it, it is sent to several
+// endpoints instead.
+func (pool *endpointPool) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		if body, err = ioutil.ReadAll(req.Body); err != nil {
+			return nil, err
+		}
+		req.Body.Close()
+	}
+
+	if quorum, ok := pool.quorum(req.Context()); ok {
+		if resp, ok, err := pool.roundTripQuorum(req, body, quorum); ok {
+			return resp, err
+		}
+	}
+
+	var lastErr error
+	for _, endpoint := range pool.order() {
+		resp, err := pool.send(req, endpoint, body)
+		if err != nil {
+			// The context of the request is done, so no other endpoint can
+			// succeed either
+			if req.Context().Err() != nil {
+				return nil, req.Context().Err()
+			}
+			lastErr = err
+			continue
+		}
+		return resp, nil
+	}
+	return nil, lastErr
+}
+
+// send the request with the given body to a single endpoint, and record
+// whether it succeeded. Server errors are returned as errors.
+func (pool *endpointPool) send(req *http.Request, endpoint *endpoint, body []byte) (*http.Response, error) {
+	endpointReq := req.Clone(req.Context())
+	endpointReq.URL = endpoint.url
+	endpointReq.Host = ""
+
+	// The http.Client authenticates every request with the user info of the
+	// first endpoint, which must not be sent to any other endpoint, so every
+	// endpoint is authenticated with its own user info instead
+	if req.URL.User != nil && endpointReq.Header.Get("Authorization") == basicAuth(req.URL.User) {
+		endpointReq.Header.Del("Authorization")
+	}
+	if endpoint.url.User != nil {
+		endpointReq.Header.Set("Authorization", basicAuth(endpoint.url.User))
+	}
+	endpointReq.Body = ioutil.NopCloser(bytes.NewReader(body))
+	endpointReq.ContentLength = int64(len(body))
+
+	start := time.Now()
+	resp, err := pool.transport.RoundTrip(endpointReq)
+	if err == nil && (resp.StatusCode >= http.StatusInternalServerError || resp.StatusCode == http.StatusTooManyRequests) {
+		resp.Body.Close()
+		err = fmt.Errorf("unexpected status %v", resp.StatusCode)
+	}
+	if err != nil {
+		if req.Context().Err() == nil {
+			pool.fail(endpoint, err)
+		}
+		return nil, err
+	}
+	pool.succeed(endpoint, time.Since(start))
+	return resp, nil
+}
+
+// order returns the endpoints in the order in which they are tried. Healthy
+// endpoints are ordered by the strategy, and ejected endpoints are only tried
+// as a last resort.
+func (pool *endpointPool) order() []*endpoint {
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+
+	healthy := make([]*endpoint, 0, len(pool.endpoints))
+	ejected := make([]*endpoint, 0)
+	for i := range pool.endpoints {
+		endpoint := pool.endpoints[i]
+		if pool.options.Strategy == RoundRobinStrategy {
+			endpoint = pool.endpoints[(pool.next+i)%len(pool.endpoints)]
+		}
+		if endpoint.healthy {
+			healthy = append(healthy, endpoint)
+		} else {
+			ejected = append(ejected, endpoint)
+		}
+	}
+	pool.next = (pool.next + 1) % len(pool.endpoints)
+
+	if pool.options.Strategy == LowestLatencyStrategy {
+		sort.SliceStable(healthy, func(i, j int) bool {
+			return healthy[i].latency < healthy[j].latency
+		})
+	}
+	return append(healthy, ejected...)
+}
+
+// succeed records a successful request to the endpoint.
+func (pool *endpointPool) succeed(endpoint *endpoint, latency time.Duration) {
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+
+	endpoint.failures = 0
+	endpoint.latency = averageLatency(endpoint.latency, latency)
+
+	// Without health checks, an ejected endpoint is re-admitted as soon as
+	// it succeeds
+	if !endpoint.healthy && pool.options.HealthCheckInterval <= 0 {
+		endpoint.healthy = true
+		endpoint.err = nil
+		pool.logger.Info("endpoint re-admitted", "url", endpoint.url.Redacted())
+	}
+}
+
+// fail records a failed request to the endpoint, and ejects the endpoint if it
+// has failed too many times in a row.
+func (pool *endpointPool) fail(endpoint *endpoint, err error) {
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+
+	endpoint.failures++
+	endpoint.err = err
+	if endpoint.healthy && endpoint.failures >= pool.options.MaxFailures {
+		endpoint.healthy = false
+		pool.logger.Warn("endpoint ejected", "url", endpoint.url.Redacted(), "failures", endpoint.failures, "err", err)
+	}
+}
+
+// checkHealth checks the block number of every endpoint at the health check
+// interval, until the context is done.
+func (pool *endpointPool) checkHealth(ctx context.Context) {
+	for {
+		pool.checkHealthOnce(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(pool.options.HealthCheckInterval):
+		}
+	}
+}
+
+// checkHealthOnce checks the block number of every endpoint. Endpoints that
+// fail, or that lag behind the highest block number, are ejected. Ejected
+// endpoints that pass are re-admitted.
+func (pool *endpointPool) checkHealthOnce(ctx context.Context) {
+	type check struct {
+		block   uint64
+		latency time.Duration
+		err     error
+	}
+	checks := make([]check, len(pool.endpoints))
+
+	// Every check must finish before the next one starts
+	checkCtx, cancel := context.WithTimeout(ctx, pool.options.HealthCheckInterval)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	for i := range pool.endpoints {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			start := time.Now()
+			block, err := pool.blockNumber(checkCtx, pool.endpoints[i].url)
+			checks[i] = check{block: block, latency: time.Since(start), err: err}
+		}(i)
+	}
+	wg.Wait()
+	if ctx.Err() != nil {
+		return
+	}
+
+	highest := uint64(0)
+	for _, check := range checks {
+		if check.err == nil && check.block > highest {
+			highest = check.block
+		}
+	}
+
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+
+	for i, endpoint := range pool.endpoints {
+		check := checks[i]
+		err := check.err
+		if err == nil && check.block+pool.options.MaxBlockLag < highest {
+			err = fmt.Errorf("block %v is %v blocks behind block %v", check.block, highest-check.block, highest)
+		}
+		if err == nil {
+			endpoint.block = check.block
+			endpoint.latency = averageLatency(endpoint.latency, check.latency)
+			endpoint.failures = 0
+			endpoint.err = nil
+		} else {
+			endpoint.err = err
+		}
+
+		healthy := err == nil
+		if healthy != endpoint.healthy {
+			if healthy {
+				pool.logger.Info("endpoint re-admitted", "url", endpoint.url.Redacted(), "block", check.block)
+			} else {
+				pool.logger.Warn("endpoint ejected", "url", endpoint.url.Redacted(), "err", err)
+			}
+		}
+		endpoint.healthy = healthy
+	}
+}
+
+// blockNumber returns the block number of a single endpoint, without failing
+// over to any other endpoint. The request has the headers of the client, and
+// is authenticated with the user info of the endpoint.
+func (pool *endpointPool) blockNumber(ctx context.Context, endpointURL *url.URL) (uint64, error) {
+	request := []byte(`{"jsonrpc":"2.0","method":"eth_blockNumber","params":[],"id":1}`)
+	req, err := http.NewRequestWithContext(ctx, "POST", endpointURL.String(), bytes.NewReader(request))
+	if err != nil {
+		return 0, err
+	}
+	pool.mu.Lock()
+	req.Header = pool.headers.Clone()
+	pool.mu.Unlock()
+	req.Header.Set("Content-Type", "application/json")
+	if endpointURL.User != nil {
+		req.Header.Set("Authorization", basicAuth(endpointURL.User))
+	}
+	resp, err := pool.transport.RoundTrip(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("unexpected status %v", resp.StatusCode)
+	}
+
+	var data struct {
+		Result *hexutil.Uint64 `json:"result"`
+		Error  *struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&data); err != nil {
+		return 0, err
+	}
+	if data.Error != nil {
+		return 0, errors.New(data.Error.Message)
+	}
+	if data.Result == nil {
+		return 0, errors.New("no block number")
+	}
+	return uint64(*data.Result), nil
+}
+
+// status returns the status of every endpoint.
+func (pool *endpointPool) status() []EndpointStatus {
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+
+	statuses := make([]EndpointStatus, len(pool.endpoints))
+	for i, endpoint := range pool.endpoints {
+		statuses[i] = EndpointStatus{
+			URL:         endpoint.url.Redacted(),
+			Healthy:     endpoint.healthy,
+			BlockNumber: endpoint.block,
+			Latency:     endpoint.latency,
+			Err:         endpoint.err,
+		}
+	}
+	return statuses
+}
+
+// setLogger sets the Logger that receives the events of the pool.
+func (pool *endpointPool) setLogger(logger Logger) {
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+
+	pool.logger = logger
+}
+
+// setHeader sets an HTTP header on the health checks of the pool. Other
+// requests already have the headers of the client.
+func (pool *endpointPool) setHeader(key, value string) {
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+
+	pool.headers.Set(key, value)
+}
+
+// close stops the health checks of the pool.
+func (pool *endpointPool) close() {
+	if pool.cancel != nil {
+		pool.cancel()
+	}
+}
+
+// averageLatency returns the moving average of the latency of an endpoint
+// after a new measurement.
+func averageLatency(average, latency time.Duration) time.Duration {
+	if average == 0 {
+		return latency
+	}
+	return (3*average + latency) / 4
+}
+
+// basicAuth returns the value of the Authorization header that authenticates
+// with the user info.
+func basicAuth(user *url.Userinfo) string {
+	password, _ := user.Password()
+	return "Basic " + base64.StdEncoding.EncodeToString([]byte(user.Username()+":"+password))
+}
@@ -0,0 +1,232 @@
+package beth_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/republicprotocol/beth-go"
+)
+
+// countingHandler counts the requests that it passes to its handler.
+type countingHandler struct {
+	handler  http.Handler
+	requests int64
+}
+
+func (counter *countingHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	atomic.AddInt64(&counter.requests, 1)
+	counter.handler.ServeHTTP(w, r)
+}
+
+func (counter *countingHandler) count() int64 {
+	return atomic.LoadInt64(&counter.requests)
+}
+
+// authHandler passes requests that are authenticated with the username and
+// password to its handler, and records the headers of every request.
+type authHandler struct {
+	handler  http.Handler
+	username string
+	password string
+
+	mu      sync.Mutex
+	headers []http.Header
+}
+
+func (auth *authHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	auth.mu.Lock()
+	auth.headers = append(auth.headers, r.Header.Clone())
+	auth.mu.Unlock()
+
+	if auth.username != "" {
+		username, password, ok := r.BasicAuth()
+		if !ok || username != auth.username || password != auth.password {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+	}
+	auth.handler.ServeHTTP(w, r)
+}
+
+// values returns the values of the header in every request.
+func (auth *authHandler) values(key string) []string {
+	auth.mu.Lock()
+	defer auth.mu.Unlock()
+
+	values := []string{}
+	for _, header := range auth.headers {
+		values = append(values, header.Get(key))
+	}
+	return values
+}
+
+var _ = Describe("endpoints", func() {
+
+	var servers []*httptest.Server
+
+	serve := func(handler http.Handler) string {
+		server := httptest.NewServer(handler)
+		servers = append(servers, server)
+		return server.URL
+	}
+
+	// withUser returns the URL with the user info.
+	withUser := func(rawURL, username, password string) string {
+		return strings.Replace(rawURL, "http://", "http://"+username+":"+password+"@", 1)
+	}
+
+	unavailable := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "service unavailable", http.StatusServiceUnavailable)
+	})
+
+	BeforeEach(func() {
+		servers = nil
+	})
+
+	AfterEach(func() {
+		for _, server := range servers {
+			server.Close()
+		}
+	})
+
+	Context("when connecting to no endpoints", func() {
+		It("should return an error", func() {
+			_, err := beth.ConnectEndpoints(nil, beth.DefaultEndpointOptions())
+			Expect(err).Should(Equal(beth.ErrNoEndpoints))
+		})
+	})
+
+	Context("when connecting to an endpoint that cannot fail over", func() {
+		It("should return an error", func() {
+			_, err := beth.ConnectEndpoints([]string{"ws://localhost:8546"}, beth.DefaultEndpointOptions())
+			Expect(err).Should(HaveOccurred())
+		})
+	})
+
+	Context("when an endpoint is unavailable", func() {
+		It("should fail over to the next endpoint and eject the unavailable one", func() {
+			options := beth.DefaultEndpointOptions()
+			options.HealthCheckInterval = 0
+			options.MaxFailures = 1
+			client, err := beth.ConnectEndpoints([]string{serve(unavailable), serve(newFakeChain(100))}, options)
+			Expect(err).ShouldNot(HaveOccurred())
+			defer client.Close()
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			// Both the ethclient and the raw JSON-RPC requests fail over
+			_, err = client.EthClient().BlockNumber(ctx)
+			Expect(err).ShouldNot(HaveOccurred())
+			_, err = client.CurrentBlockNumber(ctx)
+			Expect(err).ShouldNot(HaveOccurred())
+
+			statuses := client.Endpoints()
+			Expect(statuses).Should(HaveLen(2))
+			Expect(statuses[0].Healthy).Should(BeFalse())
+			Expect(statuses[0].Err).Should(HaveOccurred())
+			Expect(statuses[1].Healthy).Should(BeTrue())
+		})
+	})
+
+	Context("when an endpoint lags behind", func() {
+		It("should eject it after a health check", func() {
+			lagging := &countingHandler{handler: newFakeChain(100)}
+			synced := &countingHandler{handler: newFakeChain(200)}
+			options := beth.DefaultEndpointOptions()
+			options.HealthCheckInterval = 10 * time.Millisecond
+			client, err := beth.ConnectEndpoints([]string{serve(lagging), serve(synced)}, options)
+			Expect(err).ShouldNot(HaveOccurred())
+			defer client.Close()
+
+			Eventually(func() bool {
+				statuses := client.Endpoints()
+				return !statuses[0].Healthy && statuses[1].Healthy
+			}, 5*time.Second).Should(BeTrue())
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			blockNumber, err := client.EthClient().BlockNumber(ctx)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(blockNumber).Should(BeNumerically(">=", 200))
+		})
+	})
+
+	Context("when using the round-robin strategy", func() {
+		It("should spread requests across the endpoints", func() {
+			first := &countingHandler{handler: newFakeChain(100)}
+			second := &countingHandler{handler: newFakeChain(100)}
+			options := beth.DefaultEndpointOptions()
+			options.Strategy = beth.RoundRobinStrategy
+			options.HealthCheckInterval = 0
+			client, err := beth.ConnectEndpoints([]string{serve(first), serve(second)}, options)
+			Expect(err).ShouldNot(HaveOccurred())
+			defer client.Close()
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			for i := 0; i < 10; i++ {
+				_, err := client.EthClient().BlockNumber(ctx)
+				Expect(err).ShouldNot(HaveOccurred())
+			}
+			Expect(first.count()).Should(BeNumerically(">=", 5))
+			Expect(second.count()).Should(BeNumerically(">=", 5))
+		})
+	})
+
+	Context("when the endpoints have user info", func() {
+		It("should authenticate every endpoint with its own user info", func() {
+			alice := &authHandler{handler: newFakeChain(100), username: "alice", password: "a"}
+			bob := &authHandler{handler: newFakeChain(100), username: "bob", password: "b"}
+			anonymous := &authHandler{handler: newFakeChain(100)}
+			options := beth.DefaultEndpointOptions()
+			options.Strategy = beth.RoundRobinStrategy
+			options.HealthCheckInterval = 0
+			client, err := beth.ConnectEndpoints([]string{withUser(serve(alice), "alice", "a"), withUser(serve(bob), "bob", "b"), serve(anonymous)}, options)
+			Expect(err).ShouldNot(HaveOccurred())
+			defer client.Close()
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			for i := 0; i < 6; i++ {
+				_, err := client.EthClient().BlockNumber(ctx)
+				Expect(err).ShouldNot(HaveOccurred())
+				_, err = client.CurrentBlockNumber(ctx)
+				Expect(err).ShouldNot(HaveOccurred())
+			}
+			Expect(bob.values("Authorization")).ShouldNot(BeEmpty())
+			Expect(anonymous.values("Authorization")).ShouldNot(BeEmpty())
+			for _, value := range anonymous.values("Authorization") {
+				Expect(value).Should(BeEmpty())
+			}
+		})
+
+		It("should authenticate the health checks and send the headers of the client", func() {
+			alice := &authHandler{handler: newFakeChain(100), username: "alice", password: "a"}
+			bob := &authHandler{handler: newFakeChain(100), username: "bob", password: "b"}
+			options := beth.DefaultEndpointOptions()
+			options.HealthCheckInterval = 10 * time.Millisecond
+			client, err := beth.ConnectEndpoints([]string{withUser(serve(alice), "alice", "a"), withUser(serve(bob), "bob", "b")}, options)
+			Expect(err).ShouldNot(HaveOccurred())
+			defer client.Close()
+			client.SetHeader("X-Api-Key", "key")
+
+			Eventually(func() []string {
+				return bob.values("X-Api-Key")
+			}, 5*time.Second).Should(ContainElement("key"))
+			for _, status := range client.Endpoints() {
+				Expect(status.Err).ShouldNot(HaveOccurred())
+				Expect(status.Healthy).Should(BeTrue())
+				Expect(status.BlockNumber).Should(BeNumerically(">=", 100))
+			}
+		})
+	})
+})
@@ -4,19 +4,36 @@ import (
 	"context"
 	"math/big"
 
+	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/core/types"
 )
 
 type erc20 struct {
 	account *account
+	address common.Address
+	abi     abi.ABI
 	cerc20  *CompatibleERC20
 }
 
 type ERC20 interface {
+	// BalanceOf returns the balance of the holder at the block carried by the
+	// context, which is the latest block by default.
 	BalanceOf(ctx context.Context, who common.Address) (*big.Int, error)
+
+	// BalancesOf returns the balances of the holders, in the same order, at
+	// the block carried by the context. The balances are read using JSON-RPC
+	// batches of the batch size of the client. If some of the balances cannot
+	// be read, their balances are nil and an *ErrBatchFailed is returned.
+	// Like every batched read, it is not read with a Quorum.
+	BalancesOf(ctx context.Context, holders []common.Address) ([]*big.Int, error)
+
+	// Allowance returns the amount that the spender can transfer from the
+	// owner at the block carried by the context.
 	Allowance(ctx context.Context, owner, spender common.Address) (*big.Int, error)
+
 	Transfer(ctx context.Context, to common.Address, amount, gasPrice *big.Int, sendAll bool) (*types.Transaction, error)
 	Approve(ctx context.Context, spender common.Address, amount, gasPrice *big.Int) (*types.Transaction, error)
 	TransferFrom(ctx context.Context, from, to common.Address, amount, gasPrice *big.Int) (*types.Transaction, error)
@@ -27,45 +44,90 @@ func (account *account) NewERC20(addressOrAlias string) (ERC20, error) {
 	if !ok {
 		address = common.HexToAddress(addressOrAlias)
 	}
-	compatibleERC20, err := NewCompatibleERC20(address, bind.ContractBackend(account.EthClient()))
+	compatibleERC20, err := NewCompatibleERC20(address, account.Backend())
+	if err != nil {
+		return nil, err
+	}
+	parsed, err := CompatibleERC20MetaData.GetAbi()
 	if err != nil {
 		return nil, err
 	}
 	return &erc20{
 		account: account,
+		address: address,
+		abi:     *parsed,
 		cerc20:  compatibleERC20,
 	}, nil
 }
 
 func (erc20 *erc20) BalanceOf(ctx context.Context, who common.Address) (*big.Int, error) {
-	client := erc20.account.Client()
 	var balance *big.Int
-	return balance, client.Get(ctx, func() error {
-		bal, err := erc20.cerc20.BalanceOf(&bind.CallOpts{}, who)
+	return balance, erc20.call(ctx, &balance, "balanceOf", who)
+}
+
+func (erc20 *erc20) BalancesOf(ctx context.Context, holders []common.Address) ([]*big.Int, error) {
+	client := erc20.account.Client()
+	block := blockRef(ctx).arg()
+	results := make([]hexutil.Bytes, len(holders))
+	requests := make([]RPCRequest, len(holders))
+	for i, holder := range holders {
+		data, err := erc20.abi.Pack("balanceOf", holder)
 		if err != nil {
-			return err
+			return nil, err
 		}
-		balance = bal
-		return nil
-	})
+		requests[i] = RPCRequest{
+			Method: "eth_call",
+			Params: []interface{}{
+				map[string]interface{}{"to": erc20.address, "data": hexutil.Bytes(data)},
+				block,
+			},
+			Result: &results[i],
+		}
+	}
+	if err := client.batchRead(ctx, requests); err != nil {
+		return nil, err
+	}
+
+	balances := make([]*big.Int, len(holders))
+	for i := range requests {
+		if requests[i].Err != nil {
+			continue
+		}
+		var balance *big.Int
+		if err := erc20.abi.UnpackIntoInterface(&balance, "balanceOf", results[i]); err != nil {
+			requests[i].Err = err
+			continue
+		}
+		balances[i] = balance
+	}
+	return balances, batchErr(requests)
 }
 
 func (erc20 *erc20) Allowance(ctx context.Context, owner, spender common.Address) (*big.Int, error) {
-	client := erc20.account.Client()
 	var allowance *big.Int
-	return allowance, client.Get(ctx, func() error {
-		alw, err := erc20.cerc20.Allowance(&bind.CallOpts{}, owner, spender)
+	return allowance, erc20.call(ctx, &allowance, "allowance", owner, spender)
+}
+
+// call reads the token by calling the method at the block carried by the
+// context, and unpacks the output of the method into the result.
+func (erc20 *erc20) call(ctx context.Context, result interface{}, method string, args ...interface{}) error {
+	data, err := erc20.abi.Pack(method, args...)
+	if err != nil {
+		return err
+	}
+	client := erc20.account.Client()
+	return client.Get(ctx, func() error {
+		returnData, err := client.callContract(ctx, erc20.address, data)
 		if err != nil {
 			return err
 		}
-		allowance = alw
-		return nil
+		return erc20.abi.UnpackIntoInterface(result, method, returnData)
 	})
 }
 
 func (erc20 *erc20) Transfer(ctx context.Context, to common.Address, amount, gasPrice *big.Int, sendAll bool) (*types.Transaction, error) {
 	if sendAll {
-		balance, err := erc20.BalanceOf(ctx, erc20.account.Address())
+		balance, err := erc20.BalanceOf(WithBlock(ctx, LatestBlock), erc20.account.Address())
 		if err != nil {
 			return nil, err
 		}
@@ -77,6 +139,8 @@ func (erc20 *erc20) Transfer(ctx context.Context, to common.Address, amount, gas
 		func(tops *bind.TransactOpts) (*types.Transaction, error) {
 			if gasPrice != nil {
 				tops.GasPrice = gasPrice
+				tops.GasFeeCap = nil
+				tops.GasTipCap = nil
 			}
 			tx, err := erc20.cerc20.Transfer(tops, to, amount)
 			if err != nil {
@@ -96,6 +160,8 @@ func (erc20 *erc20) Approve(ctx context.Context, spender common.Address, amount,
 		func(tops *bind.TransactOpts) (*types.Transaction, error) {
 			if gasPrice != nil {
 				tops.GasPrice = gasPrice
+				tops.GasFeeCap = nil
+				tops.GasTipCap = nil
 			}
 			tx, err := erc20.cerc20.Approve(tops, spender, amount)
 			if err != nil {
@@ -115,6 +181,8 @@ func (erc20 *erc20) TransferFrom(ctx context.Context, from, to common.Address, a
 		func(tops *bind.TransactOpts) (*types.Transaction, error) {
 			if gasPrice != nil {
 				tops.GasPrice = gasPrice
+				tops.GasFeeCap = nil
+				tops.GasTipCap = nil
 			}
 			tx, err := erc20.cerc20.TransferFrom(tops, from, to, amount)
 			if err != nil {
@@ -0,0 +1,244 @@
+package beth
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// txError is a class of errors returned by Ethereum nodes when a transaction
+// is sent. It determines whether sending the transaction again can succeed.
+type txError struct {
+	msg       string
+	retryable bool
+}
+
+// Error implements the error interface.
+func (err *txError) Error() string {
+	return err.msg
+}
+
+// ErrNonceTooLow indicates that the nonce of the transaction has already been
+// used. Retrying with the next nonce can succeed.
+var ErrNonceTooLow error = &txError{msg: "nonce too low", retryable: true}
+
+// ErrNonceTooHigh indicates that there is a gap between the nonce of the
+// transaction and the nonce of the account. Retrying once the nonce is in sync
+// can succeed.
+var ErrNonceTooHigh error = &txError{msg: "nonce too high", retryable: true}
+
+// ErrInvalidNonce indicates any other problem with the nonce of the
+// transaction. Retrying once the nonce is in sync can succeed.
+var ErrInvalidNonce error = &txError{msg: "invalid nonce", retryable: true}
+
+// ErrInsufficientFunds indicates that the account cannot pay for the value and
+// gas of the transaction.
+var ErrInsufficientFunds error = &txError{msg: "insufficient funds for gas * price + value", retryable: false}
+
+// ErrGasLimitExceeded indicates that the gas limit of the transaction exceeds
+// the gas limit of the block.
+var ErrGasLimitExceeded error = &txError{msg: "exceeds block gas limit", retryable: false}
+
+// ErrIntrinsicGas indicates that the gas limit of the transaction is below
+// the gas that the transaction needs before any execution.
+var ErrIntrinsicGas error = &txError{msg: "intrinsic gas too low", retryable: false}
+
+// ErrUnderpriced indicates that the gas price of the transaction is below the
+// minimum accepted by the node. Retrying with a higher gas price can succeed.
+var ErrUnderpriced error = &txError{msg: "transaction underpriced", retryable: true}
+
+// ErrReplacementUnderpriced indicates that there exists another transaction
+// with the same nonce and a higher or equal gas price.
+var ErrReplacementUnderpriced error = &txError{msg: "replacement transaction underpriced", retryable: false}
+
+// ErrFeeCapTooLow indicates that the fee cap of the transaction is below the
+// base fee of the block. Retrying with a higher fee cap can succeed.
+var ErrFeeCapTooLow error = &txError{msg: "max fee per gas less than block base fee", retryable: true}
+
+// ErrAlreadyKnown indicates that the node already has the transaction in its
+// pool, so the transaction has already been sent. Transact waits for the known
+// transaction instead of sending it again, because a new transaction would use
+// the next nonce.
+var ErrAlreadyKnown error = &txError{msg: "already known", retryable: false}
+
+// ErrTxPoolFull indicates that the pool of the node cannot accept any more
+// transactions. Retrying later can succeed.
+var ErrTxPoolFull error = &txError{msg: "transaction pool is full", retryable: true}
+
+// ErrOutOfGas indicates that the transaction was mined, but used all of its
+// gas. The gas limit is estimated against the state at the time that the
+// transaction is sent, which can change before it is mined, so retrying with a
+// new estimate can succeed.
+var ErrOutOfGas error = &txError{msg: "out of gas", retryable: true}
+
+// classifiedError is an error returned by an Ethereum node along with its
+// class. It keeps the message of the node, and unwraps to the error of the
+// node, so that both the class and the original error can be checked with
+// errors.Is and errors.As.
+type classifiedError struct {
+	class error
+	err   error
+}
+
+// Error implements the error interface.
+func (err *classifiedError) Error() string {
+	return err.err.Error()
+}
+
+// Unwrap returns the error of the node.
+func (err *classifiedError) Unwrap() error {
+	return err.err
+}
+
+// Is returns true if the target is the class of the error.
+func (err *classifiedError) Is(target error) bool {
+	return target == err.class
+}
+
+// As sets the target to the class of the error, if the target is a *txError.
+func (err *classifiedError) As(target interface{}) bool {
+	class, ok := target.(**txError)
+	if ok {
+		*class = err.class.(*txError)
+	}
+	return ok
+}
+
+// coreErrors maps the errors of the go-ethereum transaction pool to their
+// class.
+var coreErrors = []struct {
+	err   error
+	class error
+}{
+	{core.ErrNonceTooLow, ErrNonceTooLow},
+	{core.ErrNonceTooHigh, ErrNonceTooHigh},
+	{core.ErrInsufficientFunds, ErrInsufficientFunds},
+	{core.ErrGasLimit, ErrGasLimitExceeded},
+	{core.ErrIntrinsicGas, ErrIntrinsicGas},
+	{core.ErrReplaceUnderpriced, ErrReplacementUnderpriced},
+	{core.ErrUnderpriced, ErrUnderpriced},
+	{core.ErrFeeCapTooLow, ErrFeeCapTooLow},
+	{core.ErrAlreadyKnown, ErrAlreadyKnown},
+	{core.ErrTxPoolOverflow, ErrTxPoolFull},
+}
+
+// txErrorMessages maps the messages returned by geth, Erigon,
+// Parity/OpenEthereum and Nethermind to their class. Messages are matched in
+// order, in lower case, so more specific messages come first.
+var txErrorMessages = []struct {
+	substr string
+	class  error
+}{
+	// geth and Erigon
+	{"nonce too low", ErrNonceTooLow},
+	{"nonce too high", ErrNonceTooHigh},
+	{"insufficient funds", ErrInsufficientFunds},
+	{"exceeds block gas limit", ErrGasLimitExceeded},
+	{"intrinsic gas too low", ErrIntrinsicGas},
+	{"replacement transaction underpriced", ErrReplacementUnderpriced},
+	{"max fee per gas less than block base fee", ErrFeeCapTooLow},
+	{"fee cap less than block base fee", ErrFeeCapTooLow},
+	{"transaction underpriced", ErrUnderpriced},
+	{"already known", ErrAlreadyKnown},
+	{"known transaction", ErrAlreadyKnown},
+	{"txpool is full", ErrTxPoolFull},
+
+	// Parity and OpenEthereum
+	{"transaction nonce is too low", ErrNonceTooLow},
+	{"nonce is too low", ErrNonceTooLow},
+	{"nonce is too high", ErrNonceTooHigh},
+	{"there is another transaction with same nonce in the queue", ErrReplacementUnderpriced},
+	{"transaction gas price is too low", ErrUnderpriced},
+	{"transaction with the same hash was already imported", ErrAlreadyKnown},
+	{"transaction cost exceeds current gas limit", ErrGasLimitExceeded},
+	{"transaction gas is too low", ErrIntrinsicGas},
+	{"there are too many transactions in the queue", ErrTxPoolFull},
+
+	// Nethermind
+	{"oldnonce", ErrNonceTooLow},
+	{"noncegap", ErrNonceTooHigh},
+	{"nonce too far in future", ErrNonceTooHigh},
+	{"insufficientfunds", ErrInsufficientFunds},
+	{"gaslimitexceeded", ErrGasLimitExceeded},
+	{"feetoolowtocompete", ErrReplacementUnderpriced},
+	{"feetoolow", ErrUnderpriced},
+	{"alreadyknown", ErrAlreadyKnown},
+	{"txpoolfull", ErrTxPoolFull},
+
+	// The simulated backend of go-ethereum
+	{"invalid transaction nonce", ErrInvalidNonce},
+
+	// Any other client
+	{"invalid nonce", ErrInvalidNonce},
+}
+
+// txErrorCodes are the JSON-RPC error codes with which nodes reject a
+// transaction. Geth, Erigon and Nethermind use the generic server error
+// -32000, EIP-1474 defines -32003 for rejected transactions, and Parity and
+// OpenEthereum use -32010. Errors with any other code, such as invalid
+// params, are not about the transaction even if their message mentions a
+// class.
+var txErrorCodes = map[int]bool{
+	-32000: true,
+	-32003: true,
+	-32010: true,
+}
+
+// ClassifyError maps an error returned by an Ethereum node onto one of the
+// transaction errors exported by this package, so that errors.Is can be used
+// to check its class. The message of the node is kept, and the original error
+// can still be checked with errors.Is and errors.As. JSON-RPC errors are only
+// classified by their message if their code is one with which nodes reject
+// transactions. Errors that cannot be classified, and an *ErrReverted whose
+// reason happens to mention a class, are returned unchanged.
+func ClassifyError(err error) error {
+	if err == nil {
+		return nil
+	}
+	var class *txError
+	if errors.As(err, &class) {
+		return err
+	}
+	var reverted *ErrReverted
+	if errors.As(err, &reverted) {
+		return err
+	}
+
+	for _, coreError := range coreErrors {
+		if errors.Is(err, coreError.err) {
+			return &classifiedError{class: coreError.class, err: err}
+		}
+	}
+
+	var rpcErr rpc.Error
+	if errors.As(err, &rpcErr) && !txErrorCodes[rpcErr.ErrorCode()] {
+		return err
+	}
+
+	msg := strings.ToLower(err.Error())
+	for _, txErrorMessage := range txErrorMessages {
+		if strings.Contains(msg, txErrorMessage.substr) {
+			return &classifiedError{class: txErrorMessage.class, err: err}
+		}
+	}
+	return err
+}
+
+// IsRetryable returns true if sending the transaction again can succeed after
+// the given error. Errors that cannot be classified are assumed to be
+// retryable.
+func IsRetryable(err error) bool {
+	var class *txError
+	if errors.As(ClassifyError(err), &class) {
+		return class.retryable
+	}
+	return true
+}
+
+// isNonceError returns true if the error is caused by the nonce of the
+// transaction.
+func isNonceError(err error) bool {
+	return errors.Is(err, ErrNonceTooLow) || errors.Is(err, ErrNonceTooHigh) || errors.Is(err, ErrInvalidNonce)
+}
@@ -0,0 +1,114 @@
+package beth_test
+
+import (
+	"errors"
+	"fmt"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/republicprotocol/beth-go"
+)
+
+var _ = Describe("transaction errors", func() {
+
+	table := []struct {
+		client string
+		msg    string
+		class  error
+	}{
+		{"geth", "nonce too low", beth.ErrNonceTooLow},
+		{"geth", "insufficient funds for gas * price + value", beth.ErrInsufficientFunds},
+		{"geth", "exceeds block gas limit", beth.ErrGasLimitExceeded},
+		{"geth", "replacement transaction underpriced", beth.ErrReplacementUnderpriced},
+		{"geth", "transaction underpriced", beth.ErrUnderpriced},
+		{"geth", "already known", beth.ErrAlreadyKnown},
+		{"parity", "Transaction nonce is too low. Try incrementing the nonce.", beth.ErrNonceTooLow},
+		{"parity", "Transaction gas price is too low. There is another transaction with same nonce in the queue. Try increasing the gas price or incrementing the nonce.", beth.ErrReplacementUnderpriced},
+		{"parity", "Insufficient funds. The account you tried to send transaction from does not have enough funds. Required 100 and got: 0.", beth.ErrInsufficientFunds},
+		{"parity", "Transaction with the same hash was already imported.", beth.ErrAlreadyKnown},
+		{"nethermind", "OldNonce", beth.ErrNonceTooLow},
+		{"nethermind", "NonceGap", beth.ErrNonceTooHigh},
+		{"nethermind", "FeeTooLowToCompete", beth.ErrReplacementUnderpriced},
+		{"erigon", "nonce too low: address 0x0, tx: 1 state: 2", beth.ErrNonceTooLow},
+		{"the simulated backend", "invalid transaction nonce: got 4, want 3", beth.ErrInvalidNonce},
+	}
+
+	for _, entry := range table {
+		entry := entry
+
+		Context(fmt.Sprintf("when %s returns %q", entry.client, entry.msg), func() {
+			It("should classify the error and keep the message", func() {
+				err := beth.ClassifyError(errors.New(entry.msg))
+				Expect(errors.Is(err, entry.class)).Should(BeTrue())
+				Expect(err.Error()).Should(Equal(entry.msg))
+			})
+		})
+	}
+
+	Context("when go-ethereum returns a wrapped transaction pool error", func() {
+		It("should classify the error", func() {
+			err := beth.ClassifyError(fmt.Errorf("%w: address 0x0", core.ErrInsufficientFunds))
+			Expect(errors.Is(err, beth.ErrInsufficientFunds)).Should(BeTrue())
+			Expect(beth.IsRetryable(err)).Should(BeFalse())
+		})
+	})
+
+	Context("when go-ethereum returns a transaction pool error", func() {
+		It("should keep the original error", func() {
+			err := beth.ClassifyError(fmt.Errorf("%w: address 0x0, tx: 1 state: 2", core.ErrNonceTooLow))
+			Expect(errors.Is(err, beth.ErrNonceTooLow)).Should(BeTrue())
+			Expect(errors.Is(err, core.ErrNonceTooLow)).Should(BeTrue())
+			Expect(errors.Is(err, beth.ErrNonceTooHigh)).Should(BeFalse())
+		})
+	})
+
+	Context("when a node rejects a transaction with a JSON-RPC error", func() {
+		It("should classify the error by its message", func() {
+			for _, code := range []int{-32000, -32003, -32010} {
+				err := beth.ClassifyError(&beth.RPCError{Code: code, Message: "Transaction nonce is too low. Try incrementing the nonce."})
+				Expect(errors.Is(err, beth.ErrNonceTooLow)).Should(BeTrue())
+			}
+		})
+	})
+
+	Context("when a JSON-RPC error that does not reject a transaction mentions a class", func() {
+		It("should return the error unchanged", func() {
+			rpcErr := &beth.RPCError{Code: -32602, Message: "invalid argument 0: json: cannot unmarshal hex number with leading zero digits into Go struct field TransactionArgs.nonce"}
+			err := beth.ClassifyError(rpcErr)
+			Expect(err).Should(Equal(rpcErr))
+			Expect(errors.Is(err, beth.ErrInvalidNonce)).Should(BeFalse())
+		})
+	})
+
+	Context("when a message mentions the nonce, but is not a nonce error", func() {
+		It("should return the error unchanged", func() {
+			err := errors.New("execution reverted: nonce already used for this order")
+			Expect(beth.ClassifyError(err)).Should(Equal(err))
+		})
+	})
+
+	Context("when a transaction reverts with a reason that mentions a class", func() {
+		It("should return the revert unchanged", func() {
+			for _, reason := range []string{"invalid nonce", "insufficient funds"} {
+				reverted := &beth.ErrReverted{Reason: reason}
+				err := beth.ClassifyError(reverted)
+				Expect(err).Should(Equal(reverted))
+
+				var target *beth.ErrReverted
+				Expect(errors.As(err, &target)).Should(BeTrue())
+				Expect(errors.Is(err, beth.ErrInvalidNonce)).Should(BeFalse())
+				Expect(errors.Is(err, beth.ErrInsufficientFunds)).Should(BeFalse())
+			}
+		})
+	})
+
+	Context("when the error cannot be classified", func() {
+		It("should return the error unchanged and assume it is retryable", func() {
+			err := errors.New("connection refused")
+			Expect(beth.ClassifyError(err)).Should(Equal(err))
+			Expect(beth.IsRetryable(err)).Should(BeTrue())
+		})
+	})
+})
@@ -0,0 +1,396 @@
+package beth_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/republicprotocol/beth-go"
+)
+
+// fakeChain is a JSON-RPC server that serves a chain whose head advances by
+// one block every time the block number is requested.
+type fakeChain struct {
+	mu       sync.Mutex
+	head     uint64
+	forks    map[uint64]string
+	receipts map[common.Hash]*types.Receipt
+	sent     []common.Hash
+	sendErr  string
+
+	// pool of transactions that have been sent, and are known to the node
+	pool map[common.Hash]*types.Transaction
+
+	// balance of every address that has no balance in balances, and the
+	// block parameters of the balance requests
+	balance       *big.Int
+	balances      map[common.Address]*big.Int
+	balanceBlocks []string
+
+	// callBlocks are the block parameters of the calls
+	callBlocks []string
+
+	// balanceFailures is the number of times that reading the balance of an
+	// address fails, or a negative number if it always fails
+	balanceFailures map[common.Address]int
+
+	// finalizedLag is the number of blocks by which the safe and finalized
+	// blocks lag behind the head, or -1 if the chain has no such blocks
+	finalizedLag int64
+
+	// feeHistory returned by `eth_feeHistory`, or nil if the method is not
+	// available
+	feeHistory *feeHistory
+
+	// gasTipCap returned by `eth_maxPriorityFeePerGas`, or nil if the method
+	// is not available
+	gasTipCap *big.Int
+
+	// stale headers of blocks that are no longer in the chain, but can still
+	// be read by their hash
+	stale map[common.Hash]*types.Header
+
+	// network ID returned by `net_version`
+	network string
+
+	// headers of the last request
+	headers http.Header
+
+	// contracts that are called instead of the token whose balances are the
+	// balances of the chain
+	contracts map[common.Address]fakeContract
+
+	// onHead is called, with the chain locked, whenever the head advances
+	onHead func(chain *fakeChain, head uint64)
+
+	// onSend is called, with the chain locked, whenever a transaction is sent
+	onSend func(chain *fakeChain, tx *types.Transaction)
+}
+
+func newFakeChain(head uint64) *fakeChain {
+	return &fakeChain{
+		head:     head - 1,
+		forks:    map[uint64]string{},
+		receipts: map[common.Hash]*types.Receipt{},
+		pool:     map[common.Hash]*types.Transaction{},
+		balance:  big.NewInt(0),
+		network:  "1337",
+		balances: map[common.Address]*big.Int{},
+		stale:    map[common.Hash]*types.Header{},
+
+		contracts: map[common.Address]fakeContract{},
+
+		balanceFailures: map[common.Address]int{},
+		finalizedLag:    -1,
+	}
+}
+
+// balanceOf returns the balance of an address, or false if reading it fails.
+func (chain *fakeChain) balanceOf(addr common.Address) (*big.Int, bool) {
+	if failures := chain.balanceFailures[addr]; failures != 0 {
+		if failures > 0 {
+			chain.balanceFailures[addr]--
+		}
+		return nil, false
+	}
+	if balance, ok := chain.balances[addr]; ok {
+		return balance, true
+	}
+	return chain.balance, true
+}
+
+// fakeContract returns the return data of a call, or its revert data and
+// false.
+type fakeContract func(chain *fakeChain, data []byte) ([]byte, bool)
+
+// call executes a call to a contract, and returns its return data, or its
+// revert data and false. It returns an error if the state cannot be read.
+func (chain *fakeChain) call(to common.Address, data []byte) ([]byte, bool, string) {
+	if to == beth.Multicall3Address {
+		return chain.multicall(data)
+	}
+	if contract, ok := chain.contracts[to]; ok {
+		ret, ok := contract(chain, data)
+		return ret, ok, ""
+	}
+
+	// Every other contract is a token whose balances are the balances of the
+	// chain
+	if len(data) != 36 || !bytes.Equal(data[:4], crypto.Keccak256([]byte("balanceOf(address)"))[:4]) {
+		return revertData("unknown method"), false, ""
+	}
+	balance, ok := chain.balanceOf(common.BytesToAddress(data[4:]))
+	if !ok {
+		return nil, false, "missing trie node"
+	}
+	return common.LeftPadBytes(balance.Bytes(), 32), true, ""
+}
+
+// multicall executes a `Multicall3.aggregate3` call.
+func (chain *fakeChain) multicall(data []byte) ([]byte, bool, string) {
+	type call3 struct {
+		Target       common.Address
+		AllowFailure bool
+		CallData     []byte
+	}
+	type result3 struct {
+		Success    bool
+		ReturnData []byte
+	}
+	multicall3, err := abi.JSON(strings.NewReader(beth.Multicall3ABI))
+	Expect(err).ShouldNot(HaveOccurred())
+
+	if bytes.Equal(data, multicall3.Methods["getBlockNumber"].ID) {
+		return common.LeftPadBytes(new(big.Int).SetUint64(chain.head).Bytes(), 32), true, ""
+	}
+	method, err := multicall3.MethodById(data)
+	if err != nil || method.Name != "aggregate3" {
+		return revertData("unknown method"), false, ""
+	}
+	args, err := method.Inputs.Unpack(data[4:])
+	Expect(err).ShouldNot(HaveOccurred())
+	calls := *abi.ConvertType(args[0], new([]call3)).(*[]call3)
+
+	results := make([]result3, len(calls))
+	for i, call := range calls {
+		ret, ok, err := chain.call(call.Target, call.CallData)
+		if err != "" {
+			return nil, false, err
+		}
+		if !ok && !call.AllowFailure {
+			return revertData("Multicall3: call failed"), false, ""
+		}
+		results[i] = result3{Success: ok, ReturnData: ret}
+	}
+	ret, err := method.Outputs.Pack(results)
+	Expect(err).ShouldNot(HaveOccurred())
+	return ret, true, ""
+}
+
+// revertData returns the revert data of `revert(reason)`.
+func revertData(reason string) []byte {
+	stringType, err := abi.NewType("string", "", nil)
+	Expect(err).ShouldNot(HaveOccurred())
+	data, err := abi.Arguments{{Type: stringType}}.Pack(reason)
+	Expect(err).ShouldNot(HaveOccurred())
+	return append(crypto.Keccak256([]byte("Error(string)"))[:4], data...)
+}
+
+// header returns the header of the block at the given number, in the fork
+// that the block currently belongs to.
+func (chain *fakeChain) header(number uint64) *types.Header {
+	return &types.Header{
+		Number:     new(big.Int).SetUint64(number),
+		Difficulty: big.NewInt(1),
+		Extra:      []byte(chain.forks[number]),
+	}
+}
+
+// mine adds a receipt for the transaction in the block at the given number.
+func (chain *fakeChain) mine(tx *types.Transaction, number uint64) {
+	chain.receipts[tx.Hash()] = &types.Receipt{
+		Status:      types.ReceiptStatusSuccessful,
+		Logs:        []*types.Log{},
+		TxHash:      tx.Hash(),
+		GasUsed:     21000,
+		BlockHash:   chain.header(number).Hash(),
+		BlockNumber: new(big.Int).SetUint64(number),
+	}
+}
+
+// blockParam returns a block parameter as a string, which is the tag or number
+// of the block, or the EIP-1898 object that refers to the block.
+func blockParam(param json.RawMessage) string {
+	var block string
+	if json.Unmarshal(param, &block) == nil {
+		return block
+	}
+	return string(param)
+}
+
+// fakeRequest is a JSON-RPC request received by a fakeChain.
+type fakeRequest struct {
+	ID     json.RawMessage   `json:"id"`
+	Method string            `json:"method"`
+	Params []json.RawMessage `json:"params"`
+}
+
+func (chain *fakeChain) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	chain.mu.Lock()
+	defer chain.mu.Unlock()
+
+	chain.headers = r.Header.Clone()
+	if bytes.HasPrefix(bytes.TrimSpace(body), []byte("[")) {
+		var requests []fakeRequest
+		if err := json.Unmarshal(body, &requests); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		responses := make([]string, len(requests))
+		for i, request := range requests {
+			responses[i] = chain.respond(request)
+		}
+		fmt.Fprintf(w, "[%s]", strings.Join(responses, ","))
+		return
+	}
+
+	var request fakeRequest
+	if err := json.Unmarshal(body, &request); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	fmt.Fprint(w, chain.respond(request))
+}
+
+// respond returns the JSON-RPC response to a request.
+func (chain *fakeChain) respond(request fakeRequest) string {
+	rpcError := func(code int, message string) string {
+		return fmt.Sprintf(`{"jsonrpc":"2.0","id":%s,"error":{"code":%d,"message":%q}}`, request.ID, code, message)
+	}
+
+	var result interface{}
+	switch request.Method {
+	case "eth_blockNumber":
+		chain.head++
+		if chain.onHead != nil {
+			chain.onHead(chain, chain.head)
+		}
+		result = hexutil.Uint64(chain.head)
+	case "eth_getBlockByNumber":
+		var tag string
+		json.Unmarshal(request.Params[0], &tag)
+		number := chain.head
+		switch tag {
+		case "latest":
+		case "safe", "finalized":
+			if chain.finalizedLag < 0 {
+				return rpcError(-39001, tag+" block not found")
+			}
+			number -= uint64(chain.finalizedLag)
+		default:
+			hexNumber, err := hexutil.DecodeUint64(tag)
+			if err != nil {
+				return rpcError(-32602, err.Error())
+			}
+			number = hexNumber
+		}
+		if number <= chain.head {
+			result = chain.header(number)
+		}
+	case "eth_getBlockByHash":
+		var hash common.Hash
+		json.Unmarshal(request.Params[0], &hash)
+		if header, ok := chain.stale[hash]; ok {
+			result = header
+			break
+		}
+		for number := uint64(0); number <= chain.head; number++ {
+			if header := chain.header(number); header.Hash() == hash {
+				result = header
+				break
+			}
+		}
+	case "eth_feeHistory":
+		if chain.feeHistory == nil {
+			return rpcError(-32601, "the method eth_feeHistory does not exist/is not available")
+		}
+		result = chain.feeHistory
+	case "eth_maxPriorityFeePerGas":
+		if chain.gasTipCap == nil {
+			return rpcError(-32601, "the method eth_maxPriorityFeePerGas does not exist/is not available")
+		}
+		result = (*hexutil.Big)(chain.gasTipCap)
+	case "net_version":
+		result = chain.network
+	case "eth_chainId":
+		result = "0x539"
+	case "eth_getTransactionCount":
+		result = "0x0"
+	case "eth_getBalance":
+		var addr common.Address
+		json.Unmarshal(request.Params[0], &addr)
+		chain.balanceBlocks = append(chain.balanceBlocks, blockParam(request.Params[1]))
+		balance, ok := chain.balanceOf(addr)
+		if !ok {
+			return rpcError(-32000, "missing trie node")
+		}
+		result = (*hexutil.Big)(balance)
+	case "eth_call":
+		var call struct {
+			To   common.Address `json:"to"`
+			Data hexutil.Bytes  `json:"data"`
+		}
+		json.Unmarshal(request.Params[0], &call)
+		chain.callBlocks = append(chain.callBlocks, blockParam(request.Params[1]))
+		ret, ok, err := chain.call(call.To, call.Data)
+		if err != "" {
+			return rpcError(-32000, err)
+		}
+		if !ok {
+			return fmt.Sprintf(`{"jsonrpc":"2.0","id":%s,"error":{"code":3,"message":"execution reverted","data":"%s"}}`, request.ID, hexutil.Bytes(ret))
+		}
+		result = hexutil.Bytes(ret)
+	case "eth_getTransactionReceipt":
+		var hash common.Hash
+		json.Unmarshal(request.Params[0], &hash)
+		if receipt, ok := chain.receipts[hash]; ok {
+			result = receipt
+		}
+	case "eth_sendRawTransaction":
+		if chain.sendErr != "" {
+			return rpcError(-32000, chain.sendErr)
+		}
+		var data hexutil.Bytes
+		json.Unmarshal(request.Params[0], &data)
+		tx := new(types.Transaction)
+		if err := tx.UnmarshalBinary(data); err != nil {
+			return rpcError(-32602, err.Error())
+		}
+		chain.sent = append(chain.sent, tx.Hash())
+		chain.pool[tx.Hash()] = tx
+		if chain.onSend != nil {
+			chain.onSend(chain, tx)
+		}
+		result = tx.Hash()
+	case "eth_getTransactionByHash":
+		var hash common.Hash
+		json.Unmarshal(request.Params[0], &hash)
+		if tx, ok := chain.pool[hash]; ok {
+			result = tx
+		}
+	default:
+		return rpcError(-32601, "the method "+request.Method+" does not exist/is not available")
+	}
+	data, err := json.Marshal(result)
+	if err != nil {
+		return rpcError(-32603, err.Error())
+	}
+	return fmt.Sprintf(`{"jsonrpc":"2.0","id":%s,"result":%s}`, request.ID, data)
+}
+
+// newSignedTx returns a transaction signed by a random key.
+func newSignedTx() *types.Transaction {
+	key, err := crypto.GenerateKey()
+	Expect(err).ShouldNot(HaveOccurred())
+	tx, err := types.SignTx(types.NewTransaction(0, common.Address{}, big.NewInt(0), 21000, big.NewInt(1), nil), types.HomesteadSigner{}, key)
+	Expect(err).ShouldNot(HaveOccurred())
+	return tx
+}
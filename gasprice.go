@@ -0,0 +1,253 @@
+package beth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"net/http"
+	"sort"
+)
+
+// ErrNoGasPriceSuggestion is returned by a median GasPriceOracle when none of
+// its sources returned a gas price.
+var ErrNoGasPriceSuggestion = errors.New("no gas price oracle returned a suggestion")
+
+// EthGasStationURL is the endpoint used by the ethGasStation GasPriceOracle.
+const EthGasStationURL = "https://ethgasstation.info/json/ethgasAPI.json"
+
+// A GasPriceOracle suggests the gas price (in wei) that should be used for a
+// transaction to be mined within the given speed tier.
+type GasPriceOracle interface {
+	SuggestGasPrice(ctx context.Context, txSpeed TxExecutionSpeed) (*big.Int, error)
+}
+
+// SuggestedGasPrice returns the gas price that ethGasStation recommends for
+// transactions to be mined on Ethereum blockchain based on the speed provided.
+func SuggestedGasPrice(txSpeed TxExecutionSpeed) (*big.Int, error) {
+	return NewEthGasStationOracle().SuggestGasPrice(context.Background(), txSpeed)
+}
+
+type ethGasStationOracle struct {
+	url string
+}
+
+// NewEthGasStationOracle returns a GasPriceOracle that reads its suggestions
+// from the ethGasStation API.
+func NewEthGasStationOracle() GasPriceOracle {
+	return NewEthGasStationOracleWithURL(EthGasStationURL)
+}
+
+// NewEthGasStationOracleWithURL returns a GasPriceOracle that reads its
+// suggestions from an ethGasStation compatible API at the given URL.
+func NewEthGasStationOracleWithURL(url string) GasPriceOracle {
+	return &ethGasStationOracle{
+		url: url,
+	}
+}
+
+// SuggestGasPrice returns the gas price that ethGasStation recommends for the
+// given speed tier.
+func (oracle *ethGasStationOracle) SuggestGasPrice(ctx context.Context, txSpeed TxExecutionSpeed) (*big.Int, error) {
+	request, err := http.NewRequest("GET", oracle.url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("cannot build request to ethGasStation = %v", err)
+	}
+	request.Header.Set("Content-Type", "application/json")
+
+	res, err := (&http.Client{}).Do(request.WithContext(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("cannot connect to ethGasStationAPI = %v", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code %v from ethGasStation", res.StatusCode)
+	}
+
+	data := struct {
+		SafeLow float64 `json:"safeLow"`
+		Average float64 `json:"average"`
+		Fast    float64 `json:"fast"`
+		Fastest float64 `json:"fastest"`
+	}{}
+	if err = json.NewDecoder(res.Body).Decode(&data); err != nil {
+		return nil, fmt.Errorf("cannot decode response body from ethGasStation = %v", err)
+	}
+
+	// ethGasStation reports prices in tenths of a gwei
+	switch txSpeed {
+	case SafeLow:
+		return big.NewInt(int64(data.SafeLow * math.Pow10(8))), nil
+	case Average:
+		return big.NewInt(int64(data.Average * math.Pow10(8))), nil
+	case Fast:
+		return big.NewInt(int64(data.Fast * math.Pow10(8))), nil
+	case Fastest:
+		return big.NewInt(int64(data.Fastest * math.Pow10(8))), nil
+	default:
+		return nil, fmt.Errorf("invalid speed tier: %v", txSpeed)
+	}
+}
+
+type nodeGasPriceOracle struct {
+	client Client
+}
+
+// NewNodeGasPriceOracle returns a GasPriceOracle that uses the `eth_gasPrice`
+// suggestion of the node the client is connected to. The node only has a
+// single suggestion, so it is returned for every speed tier.
+func NewNodeGasPriceOracle(client Client) GasPriceOracle {
+	return &nodeGasPriceOracle{
+		client: client,
+	}
+}
+
+// SuggestGasPrice returns the gas price suggested by the node.
+func (oracle *nodeGasPriceOracle) SuggestGasPrice(ctx context.Context, txSpeed TxExecutionSpeed) (*big.Int, error) {
+	return oracle.client.Backend().SuggestGasPrice(ctx)
+}
+
+type fixedGasPriceOracle struct {
+	gasPrice *big.Int
+}
+
+// NewFixedGasPriceOracle returns a GasPriceOracle that always suggests the
+// given gas price (in wei), regardless of the speed tier.
+func NewFixedGasPriceOracle(gasPrice *big.Int) GasPriceOracle {
+	return &fixedGasPriceOracle{
+		gasPrice: new(big.Int).Set(gasPrice),
+	}
+}
+
+// SuggestGasPrice returns a copy of the fixed gas price.
+func (oracle *fixedGasPriceOracle) SuggestGasPrice(ctx context.Context, txSpeed TxExecutionSpeed) (*big.Int, error) {
+	return new(big.Int).Set(oracle.gasPrice), nil
+}
+
+type medianGasPriceOracle struct {
+	oracles []GasPriceOracle
+}
+
+// NewMedianGasPriceOracle returns a GasPriceOracle that queries all of the
+// given oracles and suggests the median of their suggestions. Oracles that
+// return an error are ignored, so a single unavailable source does not stop a
+// gas price from being suggested.
+func NewMedianGasPriceOracle(oracles ...GasPriceOracle) GasPriceOracle {
+	return &medianGasPriceOracle{
+		oracles: oracles,
+	}
+}
+
+// SuggestGasPrice returns the median of the suggestions returned by the
+// underlying oracles. When there is an even number of suggestions, the lower
+// of the two middle values is returned.
+func (oracle *medianGasPriceOracle) SuggestGasPrice(ctx context.Context, txSpeed TxExecutionSpeed) (*big.Int, error) {
+	gasPrices := make([]*big.Int, 0, len(oracle.oracles))
+	for _, source := range oracle.oracles {
+		gasPrice, err := source.SuggestGasPrice(ctx, txSpeed)
+		if err != nil || gasPrice == nil {
+			continue
+		}
+		gasPrices = append(gasPrices, gasPrice)
+	}
+	if len(gasPrices) == 0 {
+		return nil, ErrNoGasPriceSuggestion
+	}
+
+	sort.Slice(gasPrices, func(i, j int) bool {
+		return gasPrices[i].Cmp(gasPrices[j]) < 0
+	})
+	return gasPrices[(len(gasPrices)-1)/2], nil
+}
+
+// DefaultFeeHistoryBlocks is the number of recent blocks inspected by a fee
+// history FeeOracle.
+const DefaultFeeHistoryBlocks = 20
+
+// A FeeOracle suggests the EIP-1559 fee cap (`maxFeePerGas`) and tip cap
+// (`maxPriorityFeePerGas`), both in wei, that should be used for a dynamic-fee
+// transaction to be mined within the given speed tier.
+type FeeOracle interface {
+	SuggestFees(ctx context.Context, txSpeed TxExecutionSpeed) (gasFeeCap, gasTipCap *big.Int, err error)
+}
+
+type feeHistoryOracle struct {
+	client Client
+	blocks uint64
+}
+
+// NewFeeHistoryOracle returns a FeeOracle that uses `eth_feeHistory` over the
+// given number of recent blocks. The tip cap is the median of the priority
+// fees paid at the percentile of the speed tier, or the tip suggested by the
+// node if recent blocks paid no priority fees, and the fee cap allows the base
+// fee of the next block to double before the transaction is priced out.
+func NewFeeHistoryOracle(client Client, blocks uint64) FeeOracle {
+	if blocks == 0 {
+		blocks = DefaultFeeHistoryBlocks
+	}
+	return &feeHistoryOracle{
+		client: client,
+		blocks: blocks,
+	}
+}
+
+// SuggestFees returns the fee cap and tip cap for the given speed tier.
+func (oracle *feeHistoryOracle) SuggestFees(ctx context.Context, txSpeed TxExecutionSpeed) (*big.Int, *big.Int, error) {
+	percentile, err := priorityFeePercentile(txSpeed)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	feeHistory, err := oracle.client.Backend().FeeHistory(ctx, oracle.blocks, nil, []float64{percentile})
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(feeHistory.BaseFee) == 0 {
+		return nil, nil, fmt.Errorf("cannot suggest fees: fee history has no base fee")
+	}
+
+	// The last base fee in the history is the base fee of the next block
+	baseFee := feeHistory.BaseFee[len(feeHistory.BaseFee)-1]
+
+	// Empty blocks report a reward of zero, which says nothing about the tip
+	// that a transaction needs, so only non-zero rewards are used. If recent
+	// blocks paid no tips, the node suggests the tip instead.
+	tips := make([]*big.Int, 0, len(feeHistory.Reward))
+	for _, reward := range feeHistory.Reward {
+		if len(reward) > 0 && reward[0] != nil && reward[0].Sign() > 0 {
+			tips = append(tips, reward[0])
+		}
+	}
+
+	gasTipCap := big.NewInt(0)
+	if len(tips) > 0 {
+		sort.Slice(tips, func(i, j int) bool {
+			return tips[i].Cmp(tips[j]) < 0
+		})
+		gasTipCap.Set(tips[len(tips)/2])
+	} else if gasTipCap, err = oracle.client.Backend().SuggestGasTipCap(ctx); err != nil {
+		return nil, nil, err
+	}
+
+	gasFeeCap := new(big.Int).Add(new(big.Int).Mul(baseFee, big.NewInt(2)), gasTipCap)
+	return gasFeeCap, gasTipCap, nil
+}
+
+// priorityFeePercentile returns the percentile of the priority fees paid in
+// recent blocks that a transaction of the given speed tier should pay.
+func priorityFeePercentile(txSpeed TxExecutionSpeed) (float64, error) {
+	switch txSpeed {
+	case SafeLow:
+		return 10, nil
+	case Average:
+		return 30, nil
+	case Fast:
+		return 60, nil
+	case Fastest:
+		return 90, nil
+	default:
+		return 0, fmt.Errorf("invalid speed tier: %v", txSpeed)
+	}
+}
@@ -0,0 +1,235 @@
+package beth_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/republicprotocol/beth-go"
+)
+
+type erroringGasPriceOracle struct{}
+
+func (erroringGasPriceOracle) SuggestGasPrice(ctx context.Context, txSpeed beth.TxExecutionSpeed) (*big.Int, error) {
+	return nil, errors.New("gas price oracle is unavailable")
+}
+
+// fixedFeeOracle is a FeeOracle that always suggests the same fees.
+type fixedFeeOracle struct {
+	gasFeeCap *big.Int
+	gasTipCap *big.Int
+}
+
+func (oracle fixedFeeOracle) SuggestFees(ctx context.Context, txSpeed beth.TxExecutionSpeed) (*big.Int, *big.Int, error) {
+	return oracle.gasFeeCap, oracle.gasTipCap, nil
+}
+
+// recordingFeeOracle is a FeeOracle that records the last fees suggested by
+// the FeeOracle that it wraps.
+type recordingFeeOracle struct {
+	beth.FeeOracle
+
+	mu        sync.Mutex
+	gasFeeCap *big.Int
+	gasTipCap *big.Int
+}
+
+func (oracle *recordingFeeOracle) SuggestFees(ctx context.Context, txSpeed beth.TxExecutionSpeed) (*big.Int, *big.Int, error) {
+	gasFeeCap, gasTipCap, err := oracle.FeeOracle.SuggestFees(ctx, txSpeed)
+	oracle.mu.Lock()
+	defer oracle.mu.Unlock()
+	oracle.gasFeeCap, oracle.gasTipCap = gasFeeCap, gasTipCap
+	return gasFeeCap, gasTipCap, err
+}
+
+var _ = Describe("gas price oracles", func() {
+
+	Context("when using an ethGasStation oracle", func() {
+		It("should convert the suggestion of the speed tier to wei", func() {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprint(w, `{"safeLow":10,"average":20,"fast":30,"fastest":45}`)
+			}))
+			defer server.Close()
+
+			oracle := beth.NewEthGasStationOracleWithURL(server.URL)
+			gasPrice, err := oracle.SuggestGasPrice(context.Background(), beth.Fast)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(gasPrice).Should(Equal(big.NewInt(3e9)))
+			gasPrice, err = oracle.SuggestGasPrice(context.Background(), beth.Fastest)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(gasPrice).Should(Equal(big.NewInt(4.5e9)))
+		})
+
+		It("should return an error if the API is unavailable", func() {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			}))
+			defer server.Close()
+
+			_, err := beth.NewEthGasStationOracleWithURL(server.URL).SuggestGasPrice(context.Background(), beth.Fast)
+			Expect(err).Should(HaveOccurred())
+		})
+	})
+
+	Context("when using a fixed gas price oracle", func() {
+		It("should suggest the same gas price for every speed tier", func() {
+			oracle := beth.NewFixedGasPriceOracle(big.NewInt(20000000000))
+			for _, speed := range []beth.TxExecutionSpeed{beth.SafeLow, beth.Average, beth.Fast, beth.Fastest} {
+				gasPrice, err := oracle.SuggestGasPrice(context.Background(), speed)
+				Expect(err).ShouldNot(HaveOccurred())
+				Expect(gasPrice.Cmp(big.NewInt(20000000000))).Should(Equal(0))
+			}
+		})
+	})
+
+	Context("when using a median gas price oracle", func() {
+		It("should suggest the median of its sources", func() {
+			oracle := beth.NewMedianGasPriceOracle(
+				beth.NewFixedGasPriceOracle(big.NewInt(30)),
+				beth.NewFixedGasPriceOracle(big.NewInt(10)),
+				beth.NewFixedGasPriceOracle(big.NewInt(20)),
+			)
+			gasPrice, err := oracle.SuggestGasPrice(context.Background(), beth.Fast)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(gasPrice.Cmp(big.NewInt(20))).Should(Equal(0))
+		})
+
+		It("should ignore sources that return an error", func() {
+			oracle := beth.NewMedianGasPriceOracle(
+				erroringGasPriceOracle{},
+				beth.NewFixedGasPriceOracle(big.NewInt(10)),
+			)
+			gasPrice, err := oracle.SuggestGasPrice(context.Background(), beth.Fast)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(gasPrice.Cmp(big.NewInt(10))).Should(Equal(0))
+		})
+
+		It("should return an error when no source returns a gas price", func() {
+			oracle := beth.NewMedianGasPriceOracle(erroringGasPriceOracle{})
+			_, err := oracle.SuggestGasPrice(context.Background(), beth.Fast)
+			Expect(err).Should(Equal(beth.ErrNoGasPriceSuggestion))
+		})
+	})
+
+	Context("when using a fee history oracle", func() {
+		It("should suggest the median tip, and a fee cap that allows the base fee to double", func() {
+			chain := newFakeChain(100)
+			chain.feeHistory = &feeHistory{
+				OldestBlock:  (*hexutil.Big)(big.NewInt(97)),
+				Reward:       [][]*hexutil.Big{{(*hexutil.Big)(big.NewInt(3e9))}, {(*hexutil.Big)(big.NewInt(1e9))}, {(*hexutil.Big)(big.NewInt(2e9))}},
+				BaseFee:      []*hexutil.Big{(*hexutil.Big)(big.NewInt(1e9)), (*hexutil.Big)(big.NewInt(2e9)), (*hexutil.Big)(big.NewInt(3e9)), (*hexutil.Big)(big.NewInt(4e9))},
+				GasUsedRatio: []float64{0.5, 0.5, 0.5},
+			}
+			server := httptest.NewServer(chain)
+			defer server.Close()
+			client, err := beth.Connect(server.URL)
+			Expect(err).ShouldNot(HaveOccurred())
+
+			gasFeeCap, gasTipCap, err := beth.NewFeeHistoryOracle(client, 3).SuggestFees(context.Background(), beth.Fast)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(gasTipCap).Should(Equal(big.NewInt(2e9)))
+			Expect(gasFeeCap).Should(Equal(big.NewInt(10e9)))
+		})
+
+		It("should suggest the tip of the node if recent blocks paid no priority fees", func() {
+			chain := newFakeChain(100)
+			chain.feeHistory = &feeHistory{
+				OldestBlock:  (*hexutil.Big)(big.NewInt(97)),
+				Reward:       [][]*hexutil.Big{{(*hexutil.Big)(big.NewInt(0))}, {(*hexutil.Big)(big.NewInt(0))}, {}},
+				BaseFee:      []*hexutil.Big{(*hexutil.Big)(big.NewInt(1e9)), (*hexutil.Big)(big.NewInt(1e9)), (*hexutil.Big)(big.NewInt(1e9)), (*hexutil.Big)(big.NewInt(1e9))},
+				GasUsedRatio: []float64{0, 0, 0},
+			}
+			chain.gasTipCap = big.NewInt(1e9)
+			server := httptest.NewServer(chain)
+			defer server.Close()
+			client, err := beth.Connect(server.URL)
+			Expect(err).ShouldNot(HaveOccurred())
+
+			gasFeeCap, gasTipCap, err := beth.NewFeeHistoryOracle(client, 3).SuggestFees(context.Background(), beth.Fast)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(gasTipCap).Should(Equal(big.NewInt(1e9)))
+			Expect(gasFeeCap).Should(Equal(big.NewInt(3e9)))
+		})
+	})
+
+	Context("when an account has a fee oracle", func() {
+		var chain *simulatedChain
+
+		BeforeEach(func() {
+			chain = newSimulatedChain(1)
+		})
+
+		AfterEach(func() {
+			chain.close()
+		})
+
+		// transfer sends one wei to a burn address, for use with Transact.
+		transfer := func(account beth.Account) func(*bind.TransactOpts) (*types.Transaction, error) {
+			return func(txOpts *bind.TransactOpts) (*types.Transaction, error) {
+				to := common.HexToAddress("0x000000000000000000000000000000000000dEaD")
+				bound := bind.NewBoundContract(to, abi.ABI{}, nil, account.Backend(), nil)
+				txOpts.Value = big.NewInt(1)
+				txOpts.GasLimit = 21000
+				return bound.Transfer(txOpts)
+			}
+		}
+
+		It("should send dynamic-fee transactions with the suggested fees", func() {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+			defer cancel()
+
+			account := chain.newAccount(0)
+			account.SetFeeOracle(fixedFeeOracle{gasFeeCap: big.NewInt(30e9), gasTipCap: big.NewInt(2e9)})
+			result, err := account.TransactWithResult(ctx, nil, transfer(account), nil, 1)
+			Expect(err).ShouldNot(HaveOccurred())
+
+			tx, _, err := account.Backend().TransactionByHash(ctx, result.Transaction.Hash())
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(tx.Type()).Should(Equal(uint8(types.DynamicFeeTxType)))
+			Expect(tx.GasFeeCap()).Should(Equal(big.NewInt(30e9)))
+			Expect(tx.GasTipCap()).Should(Equal(big.NewInt(2e9)))
+
+			header, err := account.Backend().HeaderByHash(ctx, result.BlockHash)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(result.EffectiveGasPrice).Should(Equal(new(big.Int).Add(header.BaseFee, big.NewInt(2e9))))
+		})
+
+		It("should send dynamic-fee transactions with the fees suggested by the fee history", func() {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+			defer cancel()
+
+			account := chain.newAccount(0)
+			oracle := &recordingFeeOracle{FeeOracle: beth.NewFeeHistoryOracle(account.Client(), 0)}
+			account.SetFeeOracle(oracle)
+			result, err := account.TransactWithResult(ctx, nil, transfer(account), nil, 1)
+			Expect(err).ShouldNot(HaveOccurred())
+
+			oracle.mu.Lock()
+			defer oracle.mu.Unlock()
+			Expect(oracle.gasFeeCap).ShouldNot(BeNil())
+			tx, _, err := account.Backend().TransactionByHash(ctx, result.Transaction.Hash())
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(tx.Type()).Should(Equal(uint8(types.DynamicFeeTxType)))
+			Expect(tx.GasFeeCap()).Should(Equal(oracle.gasFeeCap))
+			Expect(tx.GasTipCap()).Should(Equal(oracle.gasTipCap))
+			Expect(tx.GasFeeCap().Cmp(tx.GasTipCap())).Should(BeNumerically(">", 0))
+
+			// The blocks of the simulated chain are empty, so the tip is
+			// suggested by the node
+			Expect(tx.GasTipCap().Sign()).Should(BeNumerically(">", 0))
+		})
+	})
+})
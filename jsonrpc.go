@@ -0,0 +1,340 @@
+package beth
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sync/atomic"
+	"time"
+
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// RPCError is an error object returned by a JSON-RPC endpoint. It implements
+// the rpc.Error and rpc.DataError interfaces of go-ethereum, so the revert
+// data of a failed call can be read from it.
+type RPCError struct {
+	Code    int             `json:"code"`
+	Message string          `json:"message"`
+	Data    json.RawMessage `json:"data,omitempty"`
+}
+
+// Error implements the error interface.
+func (err *RPCError) Error() string {
+	return fmt.Sprintf("%s (code %d)", err.Message, err.Code)
+}
+
+// ErrorCode returns the JSON-RPC error code.
+func (err *RPCError) ErrorCode() int {
+	return err.Code
+}
+
+// ErrorData returns the data of the error. Data that is a JSON string, such as
+// the revert data returned by geth, is returned as a string.
+func (err *RPCError) ErrorData() interface{} {
+	if len(err.Data) == 0 {
+		return nil
+	}
+	var data interface{}
+	if json.Unmarshal(err.Data, &data) != nil {
+		return nil
+	}
+	return data
+}
+
+// RPCRequest is a single request in a batch of JSON-RPC requests.
+type RPCRequest struct {
+	Method string
+	Params []interface{}
+
+	// Result is decoded from the result of the response. It must be a
+	// pointer, and is left unchanged if the result is null.
+	Result interface{}
+
+	// Err is set after the batch is sent if the endpoint returned an error
+	// for this request, or if its result could not be decoded.
+	Err error
+}
+
+// rpcRequestID is the ID of the last JSON-RPC request sent by any Client.
+var rpcRequestID uint64
+
+// jsonrpcMessage is a JSON-RPC 2.0 request or response.
+type jsonrpcMessage struct {
+	Version string          `json:"jsonrpc"`
+	ID      uint64          `json:"id"`
+	Method  string          `json:"method,omitempty"`
+	Params  []interface{}   `json:"params,omitempty"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *RPCError       `json:"error,omitempty"`
+}
+
+// newRequest returns a JSON-RPC request with a new ID.
+func newRequest(method string, params []interface{}) jsonrpcMessage {
+	if params == nil {
+		params = []interface{}{}
+	}
+	return jsonrpcMessage{
+		Version: "2.0",
+		ID:      atomic.AddUint64(&rpcRequestID, 1),
+		Method:  method,
+		Params:  params,
+	}
+}
+
+// decodeResult decodes the result of a response into the result of a request.
+// Null results leave the result unchanged.
+func decodeResult(response jsonrpcMessage, result interface{}) error {
+	if response.Error != nil {
+		return response.Error
+	}
+	if result == nil || len(response.Result) == 0 || string(response.Result) == "null" {
+		return nil
+	}
+	return json.Unmarshal(response.Result, result)
+}
+
+// Call sends a JSON-RPC request to the endpoint of the client, and decodes its
+// result into the result, which must be a pointer. A null result leaves the
+// result unchanged. Requests that cannot be sent are retried according to the
+// RetryPolicy of the client, but an error returned by the endpoint is returned
+// immediately as an *RPCError. Requests are sent over the transport of the
+// URL of the client, which can be HTTP, WebSocket or IPC.
+func (client *Client) Call(ctx context.Context, result interface{}, method string, params ...interface{}) error {
+	if !client.hasEndpoint() {
+		return ErrNoEndpoints
+	}
+	request := newRequest(method, params)
+	if !client.overHTTP() {
+		var response jsonrpcMessage
+		err := client.roundTrip(ctx, func() error {
+			return client.rpcClient.CallContext(ctx, &response.Result, method, request.Params...)
+		})
+		if err != nil {
+			return rpcErr(err)
+		}
+		return decodeResult(response, result)
+	}
+
+	body, err := json.Marshal(request)
+	if err != nil {
+		return err
+	}
+	respBody, err := client.post(ctx, body)
+	if err != nil {
+		return err
+	}
+
+	var response jsonrpcMessage
+	if err := json.Unmarshal(respBody, &response); err != nil {
+		return fmt.Errorf("cannot decode response to %s: %v", method, err)
+	}
+	if response.Error == nil && response.ID != request.ID {
+		return fmt.Errorf("unexpected response id %d to request %d", response.ID, request.ID)
+	}
+	return decodeResult(response, result)
+}
+
+// BatchCall sends a batch of JSON-RPC requests to the endpoint of the client
+// in a single round trip. The result, or error, of every request is set on the
+// request. An error is only returned if the batch cannot be sent. Batches are
+// sent to a single endpoint, and are not read with a Quorum.
+func (client *Client) BatchCall(ctx context.Context, requests []RPCRequest) error {
+	if len(requests) == 0 {
+		return nil
+	}
+	if !client.hasEndpoint() {
+		return ErrNoEndpoints
+	}
+	if !client.overHTTP() {
+		return client.batchCallRPC(ctx, requests)
+	}
+
+	messages := make([]jsonrpcMessage, len(requests))
+	indices := make(map[uint64]int, len(requests))
+	for i, request := range requests {
+		messages[i] = newRequest(request.Method, request.Params)
+		indices[messages[i].ID] = i
+	}
+	body, err := json.Marshal(messages)
+	if err != nil {
+		return err
+	}
+	respBody, err := client.post(ctx, body)
+	if err != nil {
+		return err
+	}
+
+	var responses []jsonrpcMessage
+	if err := json.Unmarshal(respBody, &responses); err != nil {
+		// Endpoints that do not support batches return a single error
+		var response jsonrpcMessage
+		if json.Unmarshal(respBody, &response) == nil && response.Error != nil {
+			return response.Error
+		}
+		return fmt.Errorf("cannot decode batch response: %v", err)
+	}
+	answered := make([]bool, len(requests))
+	for _, response := range responses {
+		i, ok := indices[response.ID]
+		if !ok {
+			continue
+		}
+		answered[i] = true
+		requests[i].Err = decodeResult(response, requests[i].Result)
+	}
+	for i := range requests {
+		if !answered[i] {
+			requests[i].Err = fmt.Errorf("no response to %s", requests[i].Method)
+		}
+	}
+	return nil
+}
+
+// batchCallRPC sends a batch of JSON-RPC requests using the rpc.Client of the
+// client, for transports other than HTTP.
+func (client *Client) batchCallRPC(ctx context.Context, requests []RPCRequest) error {
+	results := make([]json.RawMessage, len(requests))
+	elems := make([]rpc.BatchElem, len(requests))
+	for i, request := range requests {
+		elems[i] = rpc.BatchElem{Method: request.Method, Args: request.Params, Result: &results[i]}
+	}
+	err := client.roundTrip(ctx, func() error {
+		return client.rpcClient.BatchCallContext(ctx, elems)
+	})
+	if err != nil {
+		return rpcErr(err)
+	}
+	for i := range requests {
+		if elems[i].Error != nil {
+			requests[i].Err = rpcErr(elems[i].Error)
+			continue
+		}
+		requests[i].Err = decodeResult(jsonrpcMessage{Result: results[i]}, requests[i].Result)
+	}
+	return nil
+}
+
+// overHTTP returns true if requests are sent to the endpoint of the client as
+// HTTP POST requests, or false if they are sent using its rpc.Client over a
+// WebSocket or IPC connection.
+func (client *Client) overHTTP() bool {
+	if client.rpcClient == nil {
+		return true
+	}
+	endpointURL, err := url.Parse(client.url)
+	return err == nil && (endpointURL.Scheme == "http" || endpointURL.Scheme == "https")
+}
+
+// rpcErr returns an *RPCError for an error returned by an endpoint to the
+// rpc.Client, and the error otherwise.
+func rpcErr(err error) error {
+	var jsonErr rpc.Error
+	if !errors.As(err, &jsonErr) {
+		return err
+	}
+	rpcErr := &RPCError{Code: jsonErr.ErrorCode(), Message: err.Error()}
+	if dataErr, ok := err.(rpc.DataError); ok && dataErr.ErrorData() != nil {
+		rpcErr.Data, _ = json.Marshal(dataErr.ErrorData())
+	}
+	return rpcErr
+}
+
+// SetHeader sets an HTTP header, such as an authorization header, on every
+// request sent by the client, including the requests sent through its
+// ethclient. Credentials can also be given as the user info of the URL, which
+// is the only way to authenticate a WebSocket connection. Headers have no
+// effect on WebSocket and IPC connections.
+func (client *Client) SetHeader(key, value string) {
+	if client.headers == nil {
+		client.headers = http.Header{}
+	}
+	client.headers.Set(key, value)
+	if client.rpcClient != nil {
+		client.rpcClient.SetHeader(key, value)
+	}
+	if client.endpoints != nil {
+		client.endpoints.setHeader(key, value)
+	}
+}
+
+// post sends a JSON-RPC request body to the endpoint of the client and returns
+// the response body. It will retry until a valid response is returned, until
+// the retry policy stops retrying, or until the context times out.
+func (client *Client) post(ctx context.Context, request []byte) (body []byte, err error) {
+	err = client.roundTrip(ctx, func() (err error) {
+		body, err = func() ([]byte, error) {
+			// Create a new http POST request
+			req, err := http.NewRequestWithContext(ctx, "POST", client.url, bytes.NewReader(request))
+			if err != nil {
+				return nil, err
+			}
+			for key, values := range client.headers {
+				req.Header[key] = values
+			}
+			req.Header.Set("Content-Type", "application/json")
+
+			// Send http POST request, failing over between endpoints if
+			// the client has several
+			httpClient := client.httpClient
+			if httpClient == nil {
+				httpClient = &http.Client{}
+			}
+			resp, err := httpClient.Do(req)
+			if err != nil {
+				return nil, err
+			}
+
+			// Decode response body
+			defer resp.Body.Close()
+			if resp.StatusCode != http.StatusOK {
+				return nil, fmt.Errorf("unexpected status %v", resp.StatusCode)
+			}
+			return ioutil.ReadAll(resp.Body)
+		}()
+		return
+	})
+	return
+}
+
+// roundTrip sends a request to the endpoint of the client using send. It will
+// retry until the request is sent, until the retry policy stops retrying, or
+// until the context times out. Errors returned by the endpoint are not
+// retried.
+func (client *Client) roundTrip(ctx context.Context, send func() error) (err error) {
+
+	policy := retryPolicy(ctx, client.retryPolicy)
+
+	// Retry until the request is sent, until the policy stops retrying or
+	// until context times out
+	for attempt := 1; ; attempt++ {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+		}
+
+		start := time.Now()
+		err = send()
+		client.metrics.rpcRequest(start, err)
+		if err == nil {
+			return nil
+		}
+		var jsonErr rpc.Error
+		if errors.As(err, &jsonErr) || !policy.retryable(err) || policy.exhausted(attempt) {
+			return err
+		}
+		delay := policy.delay(attempt)
+		client.log().Debug("retrying request", "url", client.url, "err", err, "attempt", attempt, "delay", delay)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+	}
+}
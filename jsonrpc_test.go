@@ -0,0 +1,108 @@
+package beth_test
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/republicprotocol/beth-go"
+)
+
+var _ = Describe("JSON-RPC client", func() {
+
+	var chain *fakeChain
+	var counter *countingHandler
+	var server *httptest.Server
+	var client beth.Client
+	var ctx context.Context
+	var cancel context.CancelFunc
+
+	BeforeEach(func() {
+		chain = newFakeChain(100)
+		counter = &countingHandler{handler: chain}
+		server = httptest.NewServer(counter)
+		var err error
+		client, err = beth.Connect(server.URL)
+		Expect(err).ShouldNot(HaveOccurred())
+		ctx, cancel = context.WithTimeout(context.Background(), 5*time.Second)
+	})
+
+	AfterEach(func() {
+		cancel()
+		client.Close()
+		server.Close()
+	})
+
+	Context("when calling a method", func() {
+		It("should decode the result", func() {
+			var blockNumber hexutil.Uint64
+			Expect(client.Call(ctx, &blockNumber, "eth_blockNumber")).Should(Succeed())
+			Expect(uint64(blockNumber)).Should(Equal(uint64(100)))
+		})
+
+		It("should return the error of the endpoint without retrying", func() {
+			requests := counter.count()
+			err := client.Call(ctx, nil, "eth_unknown")
+
+			var rpcErr *beth.RPCError
+			Expect(errors.As(err, &rpcErr)).Should(BeTrue())
+			Expect(rpcErr.Code).Should(Equal(-32601))
+			Expect(counter.count()).Should(Equal(requests + 1))
+		})
+
+		It("should leave the result unchanged if it is null", func() {
+			receipt := map[string]interface{}{"unchanged": true}
+			Expect(client.Call(ctx, &receipt, "eth_getTransactionReceipt", common.Hash{})).Should(Succeed())
+			Expect(receipt).Should(HaveKey("unchanged"))
+		})
+	})
+
+	Context("when calling a batch of methods", func() {
+		It("should set the result or error of every request", func() {
+			var blockNumber hexutil.Uint64
+			requests := []beth.RPCRequest{
+				{Method: "eth_blockNumber", Result: &blockNumber},
+				{Method: "eth_unknown"},
+			}
+			Expect(client.BatchCall(ctx, requests)).Should(Succeed())
+			Expect(requests[0].Err).ShouldNot(HaveOccurred())
+			Expect(uint64(blockNumber)).Should(Equal(uint64(100)))
+
+			var rpcErr *beth.RPCError
+			Expect(errors.As(requests[1].Err, &rpcErr)).Should(BeTrue())
+		})
+	})
+
+	Context("when a header is set", func() {
+		It("should send it with raw requests and with requests of the ethclient", func() {
+			client.SetHeader("Authorization", "Bearer secret")
+
+			Expect(client.Call(ctx, nil, "eth_blockNumber")).Should(Succeed())
+			chain.mu.Lock()
+			Expect(chain.headers.Get("Authorization")).Should(Equal("Bearer secret"))
+			chain.headers = nil
+			chain.mu.Unlock()
+
+			_, err := client.EthClient().BlockNumber(ctx)
+			Expect(err).ShouldNot(HaveOccurred())
+			chain.mu.Lock()
+			Expect(chain.headers.Get("Authorization")).Should(Equal("Bearer secret"))
+			chain.mu.Unlock()
+		})
+	})
+
+	Context("when the endpoint does not support a block tag", func() {
+		It("should return its error instead of retrying", func() {
+			_, err := client.FinalizedBlockNumber(ctx)
+			var rpcErr *beth.RPCError
+			Expect(errors.As(err, &rpcErr)).Should(BeTrue())
+			Expect(rpcErr.Message).Should(Equal("finalized block not found"))
+		})
+	})
+})
@@ -0,0 +1,229 @@
+package beth
+
+import (
+	"crypto/ecdsa"
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"math/big"
+	"os"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/tyler-smith/go-bip39"
+)
+
+// ErrInvalidMnemonic is returned when a mnemonic is not a valid BIP-39
+// mnemonic.
+var ErrInvalidMnemonic = errors.New("invalid mnemonic")
+
+// ErrInvalidDerivationPath is returned when a BIP-32 derivation path derives an
+// invalid key. It happens for fewer than 1 in 2^127 paths, and the next index
+// should be used instead.
+var ErrInvalidDerivationPath = errors.New("derivation path derives an invalid key")
+
+// DefaultDerivationPath is the BIP-44 derivation path of the first Ethereum
+// account of a mnemonic, m/44'/60'/0'/0/0.
+const DefaultDerivationPath = "m/44'/60'/0'/0/0"
+
+// NewAccountFromKeystore returns a user account for the key in the encrypted
+// keystore file at the path, which is connected to an Ethereum client. The
+// gas price of every transaction is taken from the gasPriceOracle. If the
+// oracle is nil, gas prices are taken from ethGasStation.
+func NewAccountFromKeystore(url, path, passphrase string, gasPriceOracle GasPriceOracle) (Account, error) {
+	signer, err := NewKeystoreSigner(path, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	return newAccountWithSigner(url, signer, gasPriceOracle)
+}
+
+// NewAccountFromMnemonic returns a user account for the key derived from the
+// BIP-39 mnemonic at the BIP-32 derivation path, which is connected to an
+// Ethereum client. An empty derivation path uses the DefaultDerivationPath.
+// The gas price of every transaction is taken from the gasPriceOracle. If the
+// oracle is nil, gas prices are taken from ethGasStation.
+func NewAccountFromMnemonic(url, mnemonic, derivationPath string, gasPriceOracle GasPriceOracle) (Account, error) {
+	signer, err := NewMnemonicSigner(mnemonic, derivationPath)
+	if err != nil {
+		return nil, err
+	}
+	return newAccountWithSigner(url, signer, gasPriceOracle)
+}
+
+// NewAccountFromHex returns a user account for the hex encoded private key,
+// which is connected to an Ethereum client. The key can have a 0x prefix. The
+// gas price of every transaction is taken from the gasPriceOracle. If the
+// oracle is nil, gas prices are taken from ethGasStation.
+func NewAccountFromHex(url, hexKey string, gasPriceOracle GasPriceOracle) (Account, error) {
+	keyBytes, err := hex.DecodeString(strings.TrimPrefix(hexKey, "0x"))
+	if err != nil {
+		return nil, err
+	}
+	defer zeroBytes(keyBytes)
+	privateKey, err := crypto.ToECDSA(keyBytes)
+	if err != nil {
+		return nil, err
+	}
+	return newAccountWithSigner(url, NewPrivateKeySigner(privateKey), gasPriceOracle)
+}
+
+// newAccountWithSigner returns a user account whose transactions and messages
+// are signed by the signer, and which is connected to an Ethereum client.
+func newAccountWithSigner(url string, signer Signer, gasPriceOracle GasPriceOracle) (Account, error) {
+	client, err := Connect(url)
+	if err != nil {
+		return nil, err
+	}
+	account, err := NewAccountWithSigner(client, signer, gasPriceOracle)
+	if err != nil {
+		client.Close()
+		return nil, err
+	}
+	return account, nil
+}
+
+// NewMnemonicSigner returns a Signer for the key derived from the BIP-39
+// mnemonic at the BIP-32 derivation path. An empty derivation path uses the
+// DefaultDerivationPath. The derived key is held in memory, and the seed is
+// zeroed once the key is derived.
+func NewMnemonicSigner(mnemonic, derivationPath string) (Signer, error) {
+	if derivationPath == "" {
+		derivationPath = DefaultDerivationPath
+	}
+	path, err := accounts.ParseDerivationPath(derivationPath)
+	if err != nil {
+		return nil, err
+	}
+	seed, err := mnemonicSeed(mnemonic)
+	if err != nil {
+		return nil, err
+	}
+	defer zeroBytes(seed)
+	privateKey, err := deriveKey(seed, path)
+	if err != nil {
+		return nil, err
+	}
+	return NewPrivateKeySigner(privateKey), nil
+}
+
+// NewKeystore generates a new private key, encrypts it with the passphrase,
+// and writes it to a new keystore file at the path. It returns the address of
+// the key, and never overwrites an existing file. The private key is zeroed
+// once it is encrypted.
+func NewKeystore(path, passphrase string) (common.Address, error) {
+	privateKey, err := crypto.GenerateKey()
+	if err != nil {
+		return common.Address{}, err
+	}
+	defer zeroKey(privateKey)
+
+	address := crypto.PubkeyToAddress(privateKey.PublicKey)
+	keyJSON, err := keystore.EncryptKey(&keystore.Key{Address: address, PrivateKey: privateKey}, passphrase, keystore.StandardScryptN, keystore.StandardScryptP)
+	if err != nil {
+		return common.Address{}, err
+	}
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return common.Address{}, err
+	}
+	if _, err := file.Write(keyJSON); err != nil {
+		file.Close()
+		return common.Address{}, err
+	}
+	return address, file.Close()
+}
+
+// mnemonicSeed returns the BIP-39 seed of the mnemonic, without a passphrase.
+func mnemonicSeed(mnemonic string) ([]byte, error) {
+	mnemonic = strings.Join(strings.Fields(mnemonic), " ")
+	if !bip39.IsMnemonicValid(mnemonic) {
+		return nil, ErrInvalidMnemonic
+	}
+	seed, err := bip39.NewSeedWithErrorChecking(mnemonic, "")
+	if err != nil {
+		return nil, ErrInvalidMnemonic
+	}
+	return seed, nil
+}
+
+// deriveKey derives the private key at the BIP-32 derivation path from the
+// seed. Intermediate keys and chain codes are zeroed once they are used.
+func deriveKey(seed []byte, path accounts.DerivationPath) (*ecdsa.PrivateKey, error) {
+	n := crypto.S256().Params().N
+
+	mac := hmac.New(sha512.New, []byte("Bitcoin seed"))
+	mac.Write(seed)
+	sum := mac.Sum(nil)
+	defer zeroBytes(sum)
+	key, chainCode := new(big.Int).SetBytes(sum[:32]), sum[32:]
+	defer zeroInt(key)
+	if key.Sign() == 0 || key.Cmp(n) >= 0 {
+		return nil, ErrInvalidDerivationPath
+	}
+
+	// The data of a child is 33 bytes of the parent key, followed by the
+	// index of the child
+	data := make([]byte, 37)
+	defer zeroBytes(data)
+	keyBytes := make([]byte, 32)
+	defer zeroBytes(keyBytes)
+	for _, index := range path {
+		key.FillBytes(keyBytes)
+		if index >= 0x80000000 {
+			// Hardened children are derived from the private key
+			data[0] = 0
+			copy(data[1:33], keyBytes)
+		} else {
+			// Normal children are derived from the compressed public key
+			privateKey, err := crypto.ToECDSA(keyBytes)
+			if err != nil {
+				return nil, err
+			}
+			copy(data[:33], crypto.CompressPubkey(&privateKey.PublicKey))
+			zeroKey(privateKey)
+		}
+		binary.BigEndian.PutUint32(data[33:], index)
+
+		mac := hmac.New(sha512.New, chainCode)
+		mac.Write(data)
+		childSum := mac.Sum(nil)
+		tweak := new(big.Int).SetBytes(childSum[:32])
+		key.Add(key, tweak).Mod(key, n)
+		valid := tweak.Cmp(n) < 0 && key.Sign() != 0
+		copy(chainCode, childSum[32:])
+		zeroInt(tweak)
+		zeroBytes(childSum)
+		if !valid {
+			return nil, ErrInvalidDerivationPath
+		}
+	}
+
+	key.FillBytes(keyBytes)
+	return crypto.ToECDSA(keyBytes)
+}
+
+// zeroBytes overwrites the bytes with zeros.
+func zeroBytes(b []byte) {
+	for i := range b {
+		b[i] = 0
+	}
+}
+
+// zeroInt overwrites the integer with zeros.
+func zeroInt(x *big.Int) {
+	b := x.Bits()
+	for i := range b {
+		b[i] = 0
+	}
+}
+
+// zeroKey overwrites the private key with zeros.
+func zeroKey(privateKey *ecdsa.PrivateKey) {
+	zeroInt(privateKey.D)
+}
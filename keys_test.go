@@ -0,0 +1,103 @@
+package beth_test
+
+import (
+	"encoding/hex"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/republicprotocol/beth-go"
+)
+
+// testMnemonic is the well-known mnemonic of the development accounts of
+// Hardhat and Foundry.
+const testMnemonic = "test test test test test test test test test test test junk"
+
+var _ = Describe("keys", func() {
+
+	Context("when deriving a key from a mnemonic", func() {
+		It("should derive the BIP-44 Ethereum accounts", func() {
+			signer, err := beth.NewMnemonicSigner(testMnemonic, "")
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(signer.Address()).Should(Equal(common.HexToAddress("0xf39Fd6e51aad88F6F4ce6aB8827279cffFb92266")))
+
+			signer, err = beth.NewMnemonicSigner(testMnemonic, "m/44'/60'/0'/0/1")
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(signer.Address()).Should(Equal(common.HexToAddress("0x70997970C51812dc3A010C7d01b50e0d17dc79C8")))
+		})
+
+		It("should return ErrInvalidMnemonic if the mnemonic is not valid", func() {
+			_, err := beth.NewMnemonicSigner("test test test", "")
+			Expect(err).Should(Equal(beth.ErrInvalidMnemonic))
+			_, err = beth.NewMnemonicSigner("test test test test test test test test test test test test", "")
+			Expect(err).Should(Equal(beth.ErrInvalidMnemonic))
+		})
+
+		It("should return an error if the derivation path is not valid", func() {
+			_, err := beth.NewMnemonicSigner(testMnemonic, "m/44'/60'/x")
+			Expect(err).Should(HaveOccurred())
+		})
+	})
+
+	Context("when generating a keystore", func() {
+		var dir string
+
+		BeforeEach(func() {
+			var err error
+			dir, err = ioutil.TempDir("", "beth")
+			Expect(err).ShouldNot(HaveOccurred())
+		})
+
+		AfterEach(func() {
+			os.RemoveAll(dir)
+		})
+
+		It("should encrypt a new key that can be decrypted with the passphrase", func() {
+			path := filepath.Join(dir, "keystore.json")
+			address, err := beth.NewKeystore(path, "passphrase")
+			Expect(err).ShouldNot(HaveOccurred())
+
+			signer, err := beth.NewKeystoreSigner(path, "passphrase")
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(signer.Address()).Should(Equal(address))
+
+			// The keystore is never overwritten
+			_, err = beth.NewKeystore(path, "passphrase")
+			Expect(os.IsExist(err)).Should(BeTrue())
+		})
+	})
+
+	Context("when creating an account", func() {
+		var chain *simulatedChain
+
+		BeforeEach(func() {
+			chain = newSimulatedChain(1)
+		})
+
+		AfterEach(func() {
+			chain.close()
+		})
+
+		It("should create it from a hex encoded key", func() {
+			hexKey := "0x" + hex.EncodeToString(crypto.FromECDSA(chain.keys[0]))
+			account, err := beth.NewAccountFromHex(chain.url(), hexKey, beth.NewFixedGasPriceOracle(big.NewInt(10e9)))
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(account.Address()).Should(Equal(crypto.PubkeyToAddress(chain.keys[0].PublicKey)))
+
+			_, err = beth.NewAccountFromHex(chain.url(), "0x1234", nil)
+			Expect(err).Should(HaveOccurred())
+		})
+
+		It("should create it from a mnemonic", func() {
+			account, err := beth.NewAccountFromMnemonic(chain.url(), testMnemonic, beth.DefaultDerivationPath, nil)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(account.Address()).Should(Equal(common.HexToAddress("0xf39Fd6e51aad88F6F4ce6aB8827279cffFb92266")))
+		})
+	})
+})
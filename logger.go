@@ -0,0 +1,44 @@
+package beth
+
+import (
+	"errors"
+)
+
+// Logger receives structured events from an Account or a Client. Every event
+// has a message and a list of alternating keys and values. The method set is
+// compatible with *slog.Logger, so a *slog.Logger can be used directly.
+type Logger interface {
+	Debug(msg string, args ...interface{})
+	Info(msg string, args ...interface{})
+	Warn(msg string, args ...interface{})
+	Error(msg string, args ...interface{})
+}
+
+// nopLogger is the default Logger, which discards all events.
+type nopLogger struct{}
+
+func (nopLogger) Debug(msg string, args ...interface{}) {}
+func (nopLogger) Info(msg string, args ...interface{})  {}
+func (nopLogger) Warn(msg string, args ...interface{})  {}
+func (nopLogger) Error(msg string, args ...interface{}) {}
+
+// NopLogger returns a Logger that discards all events.
+func NopLogger() Logger {
+	return nopLogger{}
+}
+
+// errorClass returns the class of an error, as used in log events. Errors
+// that cannot be classified have an empty class.
+func errorClass(err error) string {
+	err = ClassifyError(err)
+
+	var class *txError
+	if errors.As(err, &class) {
+		return class.msg
+	}
+	var reverted *ErrReverted
+	if errors.As(err, &reverted) {
+		return "reverted"
+	}
+	return ""
+}
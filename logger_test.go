@@ -0,0 +1,230 @@
+package beth_test
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"net/http/httptest"
+	"sync"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/republicprotocol/beth-go"
+)
+
+// loggedEvent is an event received by a recordingLogger.
+type loggedEvent struct {
+	level string
+	msg   string
+	args  []interface{}
+}
+
+// arg returns the value of the key in the arguments of the event, or nil if
+// the event has no such key.
+func (event loggedEvent) arg(key string) interface{} {
+	for i := 0; i+1 < len(event.args); i += 2 {
+		if event.args[i] == key {
+			return event.args[i+1]
+		}
+	}
+	return nil
+}
+
+// recordingLogger is a Logger that records every event it receives.
+type recordingLogger struct {
+	mu     *sync.Mutex
+	events []loggedEvent
+}
+
+func newRecordingLogger() *recordingLogger {
+	return &recordingLogger{
+		mu: new(sync.Mutex),
+	}
+}
+
+func (logger *recordingLogger) Debug(msg string, args ...interface{}) {
+	logger.record("debug", msg, args)
+}
+
+func (logger *recordingLogger) Info(msg string, args ...interface{}) {
+	logger.record("info", msg, args)
+}
+
+func (logger *recordingLogger) Warn(msg string, args ...interface{}) {
+	logger.record("warn", msg, args)
+}
+
+func (logger *recordingLogger) Error(msg string, args ...interface{}) {
+	logger.record("error", msg, args)
+}
+
+func (logger *recordingLogger) record(level, msg string, args []interface{}) {
+	logger.mu.Lock()
+	defer logger.mu.Unlock()
+
+	logger.events = append(logger.events, loggedEvent{level: level, msg: msg, args: args})
+}
+
+// messages returns the messages of the recorded events at the level.
+func (logger *recordingLogger) messages(level string) []string {
+	logger.mu.Lock()
+	defer logger.mu.Unlock()
+
+	msgs := []string{}
+	for _, event := range logger.events {
+		if event.level == level {
+			msgs = append(msgs, event.msg)
+		}
+	}
+	return msgs
+}
+
+// event returns the first recorded event with the message, or false if no
+// such event has been recorded.
+func (logger *recordingLogger) event(msg string) (loggedEvent, bool) {
+	logger.mu.Lock()
+	defer logger.mu.Unlock()
+
+	for _, event := range logger.events {
+		if event.msg == msg {
+			return event, true
+		}
+	}
+	return loggedEvent{}, false
+}
+
+var _ = Describe("loggers", func() {
+
+	Context("when an account transacts", func() {
+		var chain *simulatedChain
+
+		BeforeEach(func() {
+			chain = newSimulatedChain(1)
+		})
+
+		AfterEach(func() {
+			chain.close()
+		})
+
+		It("should log the progress of the transaction", func() {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+			defer cancel()
+
+			account := chain.newAccount(0)
+			logger := newRecordingLogger()
+			account.SetLogger(logger)
+
+			to := common.HexToAddress("0x000000000000000000000000000000000000dEaD")
+			tx, err := account.Transact(ctx, nil, func(txOpts *bind.TransactOpts) (*types.Transaction, error) {
+				bound := bind.NewBoundContract(to, abi.ABI{}, nil, account.Backend(), nil)
+				txOpts.Value = big.NewInt(1)
+				txOpts.GasLimit = 21000
+				return bound.Transfer(txOpts)
+			}, nil, 1)
+			Expect(err).ShouldNot(HaveOccurred())
+
+			Expect(logger.messages("debug")).Should(ContainElement("gas price updated"))
+			sent, ok := logger.event("transaction sent")
+			Expect(ok).Should(BeTrue())
+			Expect(sent.level).Should(Equal("debug"))
+			Expect(sent.arg("hash")).Should(Equal(tx.Hash()))
+			Expect(sent.arg("nonce")).Should(Equal(tx.Nonce()))
+			Expect(sent.arg("attempt")).Should(Equal(1))
+			Expect(sent.arg("gasPrice")).Should(Equal(big.NewInt(10e9)))
+			mined, ok := logger.event("transaction mined")
+			Expect(ok).Should(BeTrue())
+			Expect(mined.level).Should(Equal("info"))
+			Expect(mined.arg("hash")).Should(Equal(tx.Hash()))
+			Expect(logger.messages("debug")).Should(ContainElement("transaction confirmed"))
+		})
+	})
+
+	Context("when an attempt fails", func() {
+		var chain *fakeChain
+		var server *httptest.Server
+		var account beth.Account
+		var logger *recordingLogger
+
+		BeforeEach(func() {
+			chain = newFakeChain(100)
+			server = httptest.NewServer(chain)
+
+			key, err := crypto.GenerateKey()
+			Expect(err).ShouldNot(HaveOccurred())
+			account, err = beth.NewAccount(server.URL, key, beth.NewFixedGasPriceOracle(big.NewInt(10e9)))
+			Expect(err).ShouldNot(HaveOccurred())
+			logger = newRecordingLogger()
+			account.SetLogger(logger)
+		})
+
+		AfterEach(func() {
+			server.Close()
+		})
+
+		It("should log the failed attempt with the class of its error", func() {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+			defer cancel()
+
+			_, err := account.Transact(ctx, nil, func(txOpts *bind.TransactOpts) (*types.Transaction, error) {
+				return nil, errors.New("txpool is full")
+			}, func() bool { return true }, 0)
+			Expect(err).ShouldNot(HaveOccurred())
+
+			failed, ok := logger.event("transaction attempt failed")
+			Expect(ok).Should(BeTrue())
+			Expect(failed.level).Should(Equal("warn"))
+			Expect(failed.arg("attempt")).Should(Equal(1))
+			Expect(failed.arg("class")).Should(Equal(beth.ErrTxPoolFull.Error()))
+		})
+
+		It("should log the retries of reads", func() {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+			defer cancel()
+
+			client := account.Client()
+			calls := 0
+			err := client.Get(beth.WithRetryPolicy(ctx, beth.RetryPolicy{InitialDelay: time.Millisecond}), func() error {
+				if calls++; calls < 2 {
+					return errors.New("read failed")
+				}
+				return nil
+			})
+			Expect(err).ShouldNot(HaveOccurred())
+
+			retry, ok := logger.event("retrying read")
+			Expect(ok).Should(BeTrue())
+			Expect(retry.level).Should(Equal("debug"))
+			Expect(retry.arg("attempt")).Should(Equal(1))
+		})
+
+		It("should log the transactions that the tracker sends again", func() {
+			tx := newSignedTx()
+			chain.mu.Lock()
+			chain.mine(tx, 100)
+			receipt := chain.receipts[tx.Hash()]
+			delete(chain.receipts, tx.Hash())
+			chain.mu.Unlock()
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			for event := range account.ConfirmationTracker().TrackTransaction(ctx, tx, receipt, 1) {
+				if event.Status == beth.Dropped {
+					cancel()
+				}
+			}
+
+			dropped, ok := logger.event("transaction dropped from the chain, sending it again")
+			Expect(ok).Should(BeTrue())
+			Expect(dropped.level).Should(Equal("warn"))
+			Expect(dropped.arg("hash")).Should(Equal(tx.Hash()))
+			Expect(logger.messages("debug")).Should(ContainElement("cannot subscribe to new heads, polling instead"))
+		})
+	})
+})
@@ -0,0 +1,215 @@
+package beth
+
+import (
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Metrics collects measurements of the transactions and reads made by an
+// Account or a Client. The collectors are registered with a
+// prometheus.Registerer, so they can be exposed by a Prometheus server or
+// gathered from an in-memory prometheus.Registry. A nil *Metrics discards all
+// measurements.
+type Metrics struct {
+	transactAttempts     prometheus.Counter
+	transactRetries      prometheus.Counter
+	nonceCorrections     *prometheus.CounterVec
+	transactReplacements prometheus.Counter
+	transactReverts      prometheus.Counter
+	timeToMine           prometheus.Histogram
+	timeToConfirm        prometheus.Histogram
+
+	getAttempts prometheus.Counter
+	getRetries  prometheus.Counter
+
+	rpcRequests       *prometheus.CounterVec
+	rpcRequestLatency prometheus.Histogram
+
+	gasPrice     *prometheus.GaugeVec
+	pendingNonce *prometheus.GaugeVec
+}
+
+// NewMetrics returns Metrics with collectors in the "beth" namespace, which
+// are registered with the given registerer.
+func NewMetrics(registerer prometheus.Registerer) (*Metrics, error) {
+	metrics := &Metrics{
+		transactAttempts: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: "beth",
+			Name:      "transact_attempts_total",
+			Help:      "Number of attempts to execute a transaction.",
+		}),
+		transactRetries: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: "beth",
+			Name:      "transact_retries_total",
+			Help:      "Number of attempts to execute a transaction after the first attempt failed.",
+		}),
+		nonceCorrections: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "beth",
+			Name:      "nonce_corrections_total",
+			Help:      "Number of times that the nonce of a transaction was corrected.",
+		}, []string{"reason"}),
+		transactReplacements: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: "beth",
+			Name:      "transact_replacements_total",
+			Help:      "Number of replacement transactions that were sent.",
+		}),
+		transactReverts: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: "beth",
+			Name:      "transact_reverts_total",
+			Help:      "Number of transactions that were mined, but reverted.",
+		}),
+		timeToMine: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: "beth",
+			Name:      "transact_mine_seconds",
+			Help:      "Time from sending a transaction until it is mined.",
+			Buckets:   prometheus.ExponentialBuckets(1, 2, 12),
+		}),
+		timeToConfirm: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: "beth",
+			Name:      "transact_confirmation_seconds",
+			Help:      "Time from sending a transaction until it has the required confirmations.",
+			Buckets:   prometheus.ExponentialBuckets(1, 2, 12),
+		}),
+
+		getAttempts: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: "beth",
+			Name:      "get_attempts_total",
+			Help:      "Number of attempts to execute a read.",
+		}),
+		getRetries: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: "beth",
+			Name:      "get_retries_total",
+			Help:      "Number of attempts to execute a read after the first attempt failed.",
+		}),
+
+		rpcRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "beth",
+			Name:      "rpc_requests_total",
+			Help:      "Number of raw JSON-RPC requests sent to the node.",
+		}, []string{"result"}),
+		rpcRequestLatency: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: "beth",
+			Name:      "rpc_request_seconds",
+			Help:      "Latency of raw JSON-RPC requests sent to the node.",
+			Buckets:   prometheus.DefBuckets,
+		}),
+
+		gasPrice: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: "beth",
+			Name:      "gas_price_wei",
+			Help:      "Gas price, or fee cap, used by the transactions of an account.",
+		}, []string{"account"}),
+		pendingNonce: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: "beth",
+			Name:      "pending_nonce",
+			Help:      "Next nonce that will be used by an account.",
+		}, []string{"account"}),
+	}
+
+	collectors := []prometheus.Collector{
+		metrics.transactAttempts,
+		metrics.transactRetries,
+		metrics.nonceCorrections,
+		metrics.transactReplacements,
+		metrics.transactReverts,
+		metrics.timeToMine,
+		metrics.timeToConfirm,
+		metrics.getAttempts,
+		metrics.getRetries,
+		metrics.rpcRequests,
+		metrics.rpcRequestLatency,
+		metrics.gasPrice,
+		metrics.pendingNonce,
+	}
+	for _, collector := range collectors {
+		if err := registerer.Register(collector); err != nil {
+			return nil, err
+		}
+	}
+	return metrics, nil
+}
+
+func (metrics *Metrics) transactAttempt(attempt int) {
+	if metrics == nil {
+		return
+	}
+	metrics.transactAttempts.Inc()
+	if attempt > 1 {
+		metrics.transactRetries.Inc()
+	}
+}
+
+func (metrics *Metrics) nonceCorrection(reason string) {
+	if metrics == nil {
+		return
+	}
+	metrics.nonceCorrections.WithLabelValues(reason).Inc()
+}
+
+func (metrics *Metrics) replacement() {
+	if metrics == nil {
+		return
+	}
+	metrics.transactReplacements.Inc()
+}
+
+func (metrics *Metrics) revert() {
+	if metrics == nil {
+		return
+	}
+	metrics.transactReverts.Inc()
+}
+
+func (metrics *Metrics) mined(sentAt time.Time) {
+	if metrics == nil {
+		return
+	}
+	metrics.timeToMine.Observe(time.Since(sentAt).Seconds())
+}
+
+func (metrics *Metrics) confirmed(sentAt time.Time) {
+	if metrics == nil {
+		return
+	}
+	metrics.timeToConfirm.Observe(time.Since(sentAt).Seconds())
+}
+
+func (metrics *Metrics) getAttempt(attempt int) {
+	if metrics == nil {
+		return
+	}
+	metrics.getAttempts.Inc()
+	if attempt > 1 {
+		metrics.getRetries.Inc()
+	}
+}
+
+func (metrics *Metrics) rpcRequest(start time.Time, err error) {
+	if metrics == nil {
+		return
+	}
+	result := "success"
+	if err != nil {
+		result = "error"
+	}
+	metrics.rpcRequests.WithLabelValues(result).Inc()
+	metrics.rpcRequestLatency.Observe(time.Since(start).Seconds())
+}
+
+func (metrics *Metrics) setGasPrice(address common.Address, gasPrice *big.Int) {
+	if metrics == nil || gasPrice == nil {
+		return
+	}
+	value, _ := new(big.Float).SetInt(gasPrice).Float64()
+	metrics.gasPrice.WithLabelValues(address.Hex()).Set(value)
+}
+
+func (metrics *Metrics) setPendingNonce(address common.Address, nonce uint64) {
+	if metrics == nil {
+		return
+	}
+	metrics.pendingNonce.WithLabelValues(address.Hex()).Set(float64(nonce))
+}
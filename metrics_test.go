@@ -0,0 +1,136 @@
+package beth_test
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/republicprotocol/beth-go"
+)
+
+// counterValue returns the value of the counter with the given name, or zero
+// if the registry has no such counter.
+func counterValue(registry *prometheus.Registry, name string) float64 {
+	families, err := registry.Gather()
+	Expect(err).ShouldNot(HaveOccurred())
+	for _, family := range families {
+		if family.GetName() != name {
+			continue
+		}
+		value := 0.0
+		for _, metric := range family.GetMetric() {
+			value += metric.GetCounter().GetValue()
+		}
+		return value
+	}
+	return 0
+}
+
+// gaugeValue returns the value of the gauge with the given name and labels, or
+// zero if the registry has no such gauge.
+func gaugeValue(registry *prometheus.Registry, name string, labels map[string]string) float64 {
+	families, err := registry.Gather()
+	Expect(err).ShouldNot(HaveOccurred())
+	for _, family := range families {
+		if family.GetName() != name {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			matched := true
+			for _, label := range metric.GetLabel() {
+				if labels[label.GetName()] != label.GetValue() {
+					matched = false
+				}
+			}
+			if matched {
+				return metric.GetGauge().GetValue()
+			}
+		}
+	}
+	return 0
+}
+
+var _ = Describe("metrics", func() {
+
+	Context("when registering metrics", func() {
+		It("should return an error when the metrics are already registered", func() {
+			registry := prometheus.NewRegistry()
+			_, err := beth.NewMetrics(registry)
+			Expect(err).ShouldNot(HaveOccurred())
+			_, err = beth.NewMetrics(registry)
+			Expect(err).Should(HaveOccurred())
+		})
+	})
+
+	Context("when a client reads with metrics", func() {
+		It("should count the attempts and retries", func() {
+			registry := prometheus.NewRegistry()
+			metrics, err := beth.NewMetrics(registry)
+			Expect(err).ShouldNot(HaveOccurred())
+
+			client := beth.Client{}
+			client.SetMetrics(metrics)
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			calls := 0
+			err = client.Get(ctx, func() error {
+				calls++
+				if calls < 2 {
+					return errors.New("read failed")
+				}
+				return nil
+			})
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(counterValue(registry, "beth_get_attempts_total")).Should(Equal(2.0))
+			Expect(counterValue(registry, "beth_get_retries_total")).Should(Equal(1.0))
+		})
+	})
+
+	Context("when an account transacts with metrics", func() {
+		var chain *simulatedChain
+
+		BeforeEach(func() {
+			chain = newSimulatedChain(1)
+		})
+
+		AfterEach(func() {
+			chain.close()
+		})
+
+		It("should count the attempts and set the pending nonce", func() {
+			registry := prometheus.NewRegistry()
+			metrics, err := beth.NewMetrics(registry)
+			Expect(err).ShouldNot(HaveOccurred())
+			account := chain.newAccount(0)
+			account.SetMetrics(metrics)
+
+			ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+			defer cancel()
+			nonce, err := account.Backend().PendingNonceAt(ctx, account.Address())
+			Expect(err).ShouldNot(HaveOccurred())
+			to := common.HexToAddress("0x000000000000000000000000000000000000dEaD")
+			for i := 0; i < 2; i++ {
+				_, err := account.Transfer(ctx, to, big.NewInt(1), nil, 0, false)
+				Expect(err).ShouldNot(HaveOccurred())
+			}
+			Expect(counterValue(registry, "beth_transact_attempts_total")).Should(Equal(2.0))
+			Expect(counterValue(registry, "beth_transact_retries_total")).Should(Equal(0.0))
+			Expect(gaugeValue(registry, "beth_pending_nonce", map[string]string{"account": account.Address().Hex()})).Should(Equal(float64(nonce + 2)))
+			Expect(gaugeValue(registry, "beth_gas_price_wei", map[string]string{"account": account.Address().Hex()})).Should(Equal(10e9))
+		})
+	})
+
+	Context("when a client reads without metrics", func() {
+		It("should not panic", func() {
+			client := beth.Client{}
+			Expect(client.Get(context.Background(), func() error { return nil })).Should(Succeed())
+		})
+	})
+})
@@ -0,0 +1,155 @@
+package beth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// ErrMulticallNotDeployed is returned when there is no Multicall3 contract at
+// the multicall address of the client.
+var ErrMulticallNotDeployed = errors.New("multicall contract is not deployed")
+
+// Multicall3Address is the address at which Multicall3 is deployed on most
+// chains. A different address can be written to the address book of the client
+// under the "Multicall3" key.
+var Multicall3Address = common.HexToAddress("0xcA11bde05977b3631167028862bE2a173976CA11")
+
+// Multicall3ABI is the ABI of the Multicall3 functions used by Multicall.
+const Multicall3ABI = `[{"inputs":[{"components":[{"name":"target","type":"address"},{"name":"allowFailure","type":"bool"},{"name":"callData","type":"bytes"}],"name":"calls","type":"tuple[]"}],"name":"aggregate3","outputs":[{"components":[{"name":"success","type":"bool"},{"name":"returnData","type":"bytes"}],"name":"returnData","type":"tuple[]"}],"stateMutability":"payable","type":"function"},{"inputs":[],"name":"getBlockNumber","outputs":[{"name":"blockNumber","type":"uint256"}],"stateMutability":"view","type":"function"}]`
+
+// multicall3 is the parsed Multicall3ABI.
+var multicall3 = func() abi.ABI {
+	parsed, err := abi.JSON(strings.NewReader(Multicall3ABI))
+	if err != nil {
+		panic(err)
+	}
+	return parsed
+}()
+
+// Call is a read of a contract that is aggregated with other reads by
+// Multicall.
+type Call struct {
+	Target common.Address
+	ABI    *abi.ABI
+	Method string
+	Args   []interface{}
+
+	// AllowFailure lets the other calls succeed if this call reverts. If a
+	// call that does not allow failure reverts, Multicall returns an error.
+	AllowFailure bool
+}
+
+// CallResult is the result of a Call aggregated by Multicall.
+type CallResult struct {
+	Success    bool
+	ReturnData []byte
+
+	// Outputs of the method of the call, decoded from the return data. They
+	// are nil if the call reverted.
+	Outputs []interface{}
+
+	// Err is an *ErrReverted if the call reverted, or the error that decoding
+	// the return data returned.
+	Err error
+}
+
+// multicallAddress returns the address of Multicall3 in the address book of
+// the client, or the Multicall3Address.
+func (client *Client) multicallAddress() common.Address {
+	if address, ok := client.addrBook["Multicall3"]; ok {
+		return address
+	}
+	return Multicall3Address
+}
+
+// Multicall aggregates the calls into a single `Multicall3.aggregate3` call at
+// the given block, or at the block carried by the context if the block number
+// is nil, so that all calls read the same state. It returns the result of
+// every call, in the same order, and the number of the block that was read.
+func (client *Client) Multicall(ctx context.Context, blockNumber *big.Int, calls []Call) ([]CallResult, *big.Int, error) {
+	type call3 struct {
+		Target       common.Address
+		AllowFailure bool
+		CallData     []byte
+	}
+	type result3 struct {
+		Success    bool
+		ReturnData []byte
+	}
+	address := client.multicallAddress()
+
+	// The first call reads the number of the block that is read
+	blockNumberData, err := multicall3.Pack("getBlockNumber")
+	if err != nil {
+		return nil, nil, err
+	}
+	aggregated := make([]call3, len(calls)+1)
+	aggregated[0] = call3{Target: address, CallData: blockNumberData}
+	for i, call := range calls {
+		if call.ABI == nil {
+			return nil, nil, fmt.Errorf("call %v to %v has no abi", i, call.Target.Hex())
+		}
+		data, err := call.ABI.Pack(call.Method, call.Args...)
+		if err != nil {
+			return nil, nil, fmt.Errorf("cannot pack call %v to %s: %v", i, call.Method, err)
+		}
+		aggregated[i+1] = call3{Target: call.Target, AllowFailure: call.AllowFailure, CallData: data}
+	}
+	data, err := multicall3.Pack("aggregate3", aggregated)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if blockNumber != nil {
+		ctx = WithBlock(ctx, BlockAtNumber(blockNumber))
+	}
+	returnData, err := client.callContract(ctx, address, data)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(returnData) == 0 {
+		return nil, nil, ErrMulticallNotDeployed
+	}
+	outputs, err := multicall3.Unpack("aggregate3", returnData)
+	if err != nil {
+		return nil, nil, err
+	}
+	results := *abi.ConvertType(outputs[0], new([]result3)).(*[]result3)
+	if len(results) != len(aggregated) {
+		return nil, nil, fmt.Errorf("unexpected number of multicall results: expected %v, got %v", len(aggregated), len(results))
+	}
+
+	number := new(big.Int).SetBytes(results[0].ReturnData)
+	callResults := make([]CallResult, len(calls))
+	for i, call := range calls {
+		result := results[i+1]
+		callResults[i] = CallResult{Success: result.Success, ReturnData: result.ReturnData}
+		if !result.Success {
+			callResults[i].Err = UnpackRevert(result.ReturnData)
+			continue
+		}
+		callResults[i].Outputs, callResults[i].Err = call.ABI.Unpack(call.Method, result.ReturnData)
+	}
+	return callResults, number, nil
+}
+
+// callErr returns an *ErrReverted if the error of an `eth_call` has revert
+// data, and the error otherwise.
+func callErr(err error) error {
+	var rpcErr *RPCError
+	if errors.As(err, &rpcErr) {
+		if hexData, ok := rpcErr.ErrorData().(string); ok {
+			if data, decodeErr := hexutil.Decode(hexData); decodeErr == nil {
+				return UnpackRevert(data)
+			}
+		}
+	}
+	return err
+}
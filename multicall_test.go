@@ -0,0 +1,139 @@
+package beth_test
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"net/http/httptest"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/republicprotocol/beth-go"
+	"github.com/republicprotocol/beth-go/test"
+)
+
+var _ = Describe("multicall", func() {
+
+	var chain *fakeChain
+	var server *httptest.Server
+	var client beth.Client
+	var ctx context.Context
+	var cancel context.CancelFunc
+
+	var bethtestABI, erc20ABI *abi.ABI
+	bethtest := common.HexToAddress("0x0000000000000000000000000000000000000b37")
+	token := common.HexToAddress("0x0000000000000000000000000000000000000abc")
+	holder := common.HexToAddress("0x0000000000000000000000000000000000000001")
+
+	BeforeEach(func() {
+		var err error
+		bethtestABI, err = test.BethtestMetaData.GetAbi()
+		Expect(err).ShouldNot(HaveOccurred())
+		erc20ABI, err = beth.CompatibleERC20MetaData.GetAbi()
+		Expect(err).ShouldNot(HaveOccurred())
+
+		chain = newFakeChain(100)
+		chain.balances[holder] = big.NewInt(1000)
+
+		// The bethtest contract stores the values 0, 2 and 4
+		chain.contracts[bethtest] = func(chain *fakeChain, data []byte) ([]byte, bool) {
+			method, err := bethtestABI.MethodById(data)
+			Expect(err).ShouldNot(HaveOccurred())
+			switch method.Name {
+			case "size":
+				ret, err := method.Outputs.Pack(big.NewInt(3))
+				Expect(err).ShouldNot(HaveOccurred())
+				return ret, true
+			case "get":
+				args, err := method.Inputs.Unpack(data[4:])
+				Expect(err).ShouldNot(HaveOccurred())
+				index := args[0].(*big.Int)
+				if index.Cmp(big.NewInt(3)) >= 0 {
+					return revertData("index out of range"), false
+				}
+				ret, err := method.Outputs.Pack(new(big.Int).Mul(index, big.NewInt(2)), true)
+				Expect(err).ShouldNot(HaveOccurred())
+				return ret, true
+			}
+			return revertData("unknown method"), false
+		}
+
+		server = httptest.NewServer(chain)
+		client, err = beth.Connect(server.URL)
+		Expect(err).ShouldNot(HaveOccurred())
+		ctx, cancel = context.WithTimeout(context.Background(), 5*time.Second)
+	})
+
+	AfterEach(func() {
+		cancel()
+		client.Close()
+		server.Close()
+	})
+
+	Context("when aggregating calls to several contracts", func() {
+		It("should decode the result of every call at one block", func() {
+			results, blockNumber, err := client.Multicall(ctx, nil, []beth.Call{
+				{Target: bethtest, ABI: bethtestABI, Method: "size"},
+				{Target: token, ABI: erc20ABI, Method: "balanceOf", Args: []interface{}{holder}},
+				{Target: bethtest, ABI: bethtestABI, Method: "get", Args: []interface{}{big.NewInt(2)}},
+			})
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(blockNumber.Uint64()).Should(Equal(uint64(99)))
+			Expect(results).Should(HaveLen(3))
+			for _, result := range results {
+				Expect(result.Success).Should(BeTrue())
+				Expect(result.Err).ShouldNot(HaveOccurred())
+			}
+			Expect(results[0].Outputs).Should(Equal([]interface{}{big.NewInt(3)}))
+			Expect(results[1].Outputs).Should(Equal([]interface{}{big.NewInt(1000)}))
+			Expect(results[2].Outputs).Should(Equal([]interface{}{big.NewInt(4), true}))
+		})
+	})
+
+	Context("when a call that allows failure reverts", func() {
+		It("should return the revert of the call and the results of the others", func() {
+			results, _, err := client.Multicall(ctx, nil, []beth.Call{
+				{Target: bethtest, ABI: bethtestABI, Method: "get", Args: []interface{}{big.NewInt(5)}, AllowFailure: true},
+				{Target: bethtest, ABI: bethtestABI, Method: "size"},
+			})
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(results[0].Success).Should(BeFalse())
+			Expect(results[0].Outputs).Should(BeNil())
+
+			var reverted *beth.ErrReverted
+			Expect(errors.As(results[0].Err, &reverted)).Should(BeTrue())
+			Expect(reverted.Reason).Should(Equal("index out of range"))
+			Expect(results[1].Outputs).Should(Equal([]interface{}{big.NewInt(3)}))
+		})
+	})
+
+	Context("when a call that does not allow failure reverts", func() {
+		It("should return an error", func() {
+			_, _, err := client.Multicall(ctx, nil, []beth.Call{
+				{Target: bethtest, ABI: bethtestABI, Method: "get", Args: []interface{}{big.NewInt(5)}},
+				{Target: bethtest, ABI: bethtestABI, Method: "size"},
+			})
+			var reverted *beth.ErrReverted
+			Expect(errors.As(err, &reverted)).Should(BeTrue())
+			Expect(reverted.Reason).Should(Equal("Multicall3: call failed"))
+		})
+	})
+
+	Context("when the multicall address is in the address book", func() {
+		It("should call the contract at that address", func() {
+			client.WriteAddress("Multicall3", common.HexToAddress("0x0000000000000000000000000000000000000ca1"))
+			chain.contracts[common.HexToAddress("0x0000000000000000000000000000000000000ca1")] = func(*fakeChain, []byte) ([]byte, bool) {
+				return nil, true
+			}
+
+			_, _, err := client.Multicall(ctx, nil, []beth.Call{
+				{Target: bethtest, ABI: bethtestABI, Method: "size"},
+			})
+			Expect(err).Should(Equal(beth.ErrMulticallNotDeployed))
+		})
+	})
+})
@@ -0,0 +1,170 @@
+package beth
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// NonceManager hands out the nonces used by the transactions of an address. It
+// allows many transactions from the same address to be in flight at once, by
+// tracking which nonces are pending and re-using nonces of transactions that
+// were never mined.
+type NonceManager interface {
+
+	// Next reserves and returns the next nonce. Nonces that have been
+	// released are handed out again before any new nonce, so that gaps in the
+	// nonce sequence are filled.
+	Next() uint64
+
+	// Release a reserved nonce whose transaction was never sent, or was
+	// dropped, so that it can be handed out again.
+	Release(nonce uint64)
+
+	// Done marks a reserved nonce as used by a mined transaction.
+	Done(nonce uint64)
+
+	// Pending returns the number of nonces that have been reserved, but are
+	// not yet done or released.
+	Pending() int
+
+	// Sync the nonce manager with the pending nonce of the address, as seen by
+	// the Ethereum client.
+	Sync(ctx context.Context) error
+}
+
+type nonceManager struct {
+	mu      *sync.Mutex
+	client  *Client
+	address common.Address
+
+	next     uint64
+	pending  map[uint64]struct{}
+	released []uint64
+}
+
+// NewNonceManager returns a NonceManager for the given address that starts at
+// its current pending nonce.
+func NewNonceManager(ctx context.Context, client Client, address common.Address) (NonceManager, error) {
+	nonces, err := newNonceManager(ctx, &client, address)
+	if err != nil {
+		return nil, err
+	}
+	return nonces, nil
+}
+
+// newNonceManager returns a NonceManager that shares the client, so that it
+// sees the logger, metrics and retry policy of the client when they change.
+func newNonceManager(ctx context.Context, client *Client, address common.Address) (*nonceManager, error) {
+	nonces := &nonceManager{
+		mu:      new(sync.Mutex),
+		client:  client,
+		address: address,
+
+		pending:  map[uint64]struct{}{},
+		released: []uint64{},
+	}
+	if err := nonces.Sync(ctx); err != nil {
+		return nil, err
+	}
+	return nonces, nil
+}
+
+// Next reserves and returns the lowest released nonce, or the next unused
+// nonce if no nonces have been released.
+func (nonces *nonceManager) Next() uint64 {
+	nonces.mu.Lock()
+	defer nonces.mu.Unlock()
+
+	var nonce uint64
+	if len(nonces.released) > 0 {
+		nonce = nonces.released[0]
+		nonces.released = nonces.released[1:]
+	} else {
+		nonce = nonces.next
+		nonces.next++
+	}
+	nonces.pending[nonce] = struct{}{}
+	nonces.setPendingNonce()
+	return nonce
+}
+
+// Release a pending nonce so that it can be handed out again.
+func (nonces *nonceManager) Release(nonce uint64) {
+	nonces.mu.Lock()
+	defer nonces.mu.Unlock()
+
+	if _, ok := nonces.pending[nonce]; !ok {
+		return
+	}
+	delete(nonces.pending, nonce)
+	nonces.released = append(nonces.released, nonce)
+	sort.Slice(nonces.released, func(i, j int) bool {
+		return nonces.released[i] < nonces.released[j]
+	})
+	nonces.setPendingNonce()
+}
+
+// Done marks a pending nonce as used.
+func (nonces *nonceManager) Done(nonce uint64) {
+	nonces.mu.Lock()
+	defer nonces.mu.Unlock()
+
+	delete(nonces.pending, nonce)
+}
+
+// Pending returns the number of pending nonces.
+func (nonces *nonceManager) Pending() int {
+	nonces.mu.Lock()
+	defer nonces.mu.Unlock()
+
+	return len(nonces.pending)
+}
+
+// Sync the nonce manager with the pending nonce of the address. Nonces below
+// the pending nonce have been used, so they are no longer pending or
+// released. If nothing is pending, the next nonce is reset to the pending
+// nonce, otherwise it is only moved forward.
+func (nonces *nonceManager) Sync(ctx context.Context) error {
+	pendingNonce, err := nonces.client.Backend().PendingNonceAt(ctx, nonces.address)
+	if err != nil {
+		return err
+	}
+
+	nonces.mu.Lock()
+	defer nonces.mu.Unlock()
+
+	for nonce := range nonces.pending {
+		if nonce < pendingNonce {
+			delete(nonces.pending, nonce)
+		}
+	}
+	released := nonces.released[:0]
+	for _, nonce := range nonces.released {
+		if nonce >= pendingNonce {
+			released = append(released, nonce)
+		}
+	}
+	nonces.released = released
+
+	if len(nonces.pending) == 0 {
+		nonces.next = pendingNonce
+		nonces.released = nonces.released[:0]
+	} else if pendingNonce > nonces.next {
+		nonces.next = pendingNonce
+	}
+	nonces.setPendingNonce()
+	return nil
+}
+
+// setPendingNonce sets the pending nonce metric of the address to the nonce
+// that Next will hand out. It must be called while holding the lock.
+func (nonces *nonceManager) setPendingNonce() {
+	nonce := nonces.next
+	if len(nonces.released) > 0 {
+		nonce = nonces.released[0]
+	}
+	nonces.client.metrics.setPendingNonce(nonces.address, nonce)
+}
@@ -0,0 +1,179 @@
+package beth_test
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"net/http/httptest"
+	"sort"
+	"sync"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/republicprotocol/beth-go"
+)
+
+// blockingOracle is a GasPriceOracle that does not suggest a gas price until
+// it is unblocked.
+type blockingOracle struct {
+	called  chan struct{}
+	unblock chan struct{}
+}
+
+func (oracle *blockingOracle) SuggestGasPrice(ctx context.Context, txSpeed beth.TxExecutionSpeed) (*big.Int, error) {
+	oracle.called <- struct{}{}
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-oracle.unblock:
+		return big.NewInt(10e9), nil
+	}
+}
+
+var _ = Describe("nonce managers", func() {
+
+	Context("when nonces are released", func() {
+		var chain *fakeChain
+		var server *httptest.Server
+		var nonces beth.NonceManager
+
+		BeforeEach(func() {
+			chain = newFakeChain(100)
+			server = httptest.NewServer(chain)
+			client, err := beth.Connect(server.URL)
+			Expect(err).ShouldNot(HaveOccurred())
+			nonces, err = beth.NewNonceManager(context.Background(), client, common.Address{})
+			Expect(err).ShouldNot(HaveOccurred())
+		})
+
+		AfterEach(func() {
+			server.Close()
+		})
+
+		It("should hand out the lowest released nonce before any new nonce", func() {
+			Expect(nonces.Next()).Should(Equal(uint64(0)))
+			Expect(nonces.Next()).Should(Equal(uint64(1)))
+			Expect(nonces.Next()).Should(Equal(uint64(2)))
+			nonces.Release(2)
+			nonces.Release(0)
+			Expect(nonces.Pending()).Should(Equal(1))
+
+			Expect(nonces.Next()).Should(Equal(uint64(0)))
+			Expect(nonces.Next()).Should(Equal(uint64(2)))
+			Expect(nonces.Next()).Should(Equal(uint64(3)))
+			Expect(nonces.Pending()).Should(Equal(4))
+		})
+
+		It("should ignore nonces that are not pending", func() {
+			Expect(nonces.Next()).Should(Equal(uint64(0)))
+			nonces.Done(0)
+			nonces.Release(0)
+			nonces.Release(5)
+			Expect(nonces.Next()).Should(Equal(uint64(1)))
+		})
+
+		It("should reset to the pending nonce when nothing is pending", func() {
+			nonce := nonces.Next()
+			nonces.Release(nonce)
+			Expect(nonces.Sync(context.Background())).Should(Succeed())
+			Expect(nonces.Pending()).Should(Equal(0))
+			Expect(nonces.Next()).Should(Equal(uint64(0)))
+		})
+	})
+
+	Context("when an account transacts concurrently", func() {
+		var chain *simulatedChain
+
+		BeforeEach(func() {
+			chain = newSimulatedChain(1)
+		})
+
+		AfterEach(func() {
+			chain.close()
+		})
+
+		It("should use unique and sequential nonces", func() {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+			defer cancel()
+
+			account := chain.newAccount(0)
+			start, err := account.Backend().PendingNonceAt(ctx, account.Address())
+			Expect(err).ShouldNot(HaveOccurred())
+
+			to := common.HexToAddress("0x000000000000000000000000000000000000dEaD")
+			var wg sync.WaitGroup
+			used := make([]uint64, 5)
+			errs := make([]error, len(used))
+			for i := range used {
+				wg.Add(1)
+				go func(i int) {
+					defer wg.Done()
+					tx, err := account.Transfer(ctx, to, big.NewInt(1), nil, 0, false)
+					if errs[i] = err; err == nil {
+						used[i] = tx.Nonce()
+					}
+				}(i)
+			}
+			wg.Wait()
+			for _, err := range errs {
+				Expect(err).ShouldNot(HaveOccurred())
+			}
+
+			sort.Slice(used, func(i, j int) bool { return used[i] < used[j] })
+			for i, nonce := range used {
+				Expect(nonce).Should(Equal(start + uint64(i)))
+			}
+		})
+	})
+
+	Context("when the gas price oracle is slow", func() {
+		var chain *fakeChain
+		var server *httptest.Server
+
+		BeforeEach(func() {
+			chain = newFakeChain(100)
+			server = httptest.NewServer(chain)
+		})
+
+		AfterEach(func() {
+			server.Close()
+		})
+
+		It("should not lock the account while waiting for the gas price", func() {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			oracle := &blockingOracle{called: make(chan struct{}, 1), unblock: make(chan struct{})}
+			key, err := crypto.GenerateKey()
+			Expect(err).ShouldNot(HaveOccurred())
+			account, err := beth.NewAccount(server.URL, key, oracle)
+			Expect(err).ShouldNot(HaveOccurred())
+
+			done := make(chan struct{})
+			go func() {
+				defer close(done)
+				account.Transact(ctx, nil, func(*bind.TransactOpts) (*types.Transaction, error) {
+					return nil, errors.New("cannot send transaction")
+				}, func() bool { return true }, 0)
+			}()
+			<-oracle.called
+
+			// Setters lock the account, so they only return if the account
+			// is not locked while the oracle is queried
+			set := make(chan struct{})
+			go func() {
+				account.SetGasPrice(20)
+				close(set)
+			}()
+			Eventually(set).Should(BeClosed())
+			close(oracle.unblock)
+			Eventually(done).Should(BeClosed())
+		})
+	})
+})
@@ -0,0 +1,370 @@
+package beth
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"math/big"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// ErrNoAccounts is returned when an AccountPool is created without accounts.
+var ErrNoAccounts = errors.New("no accounts")
+
+// ErrNoTreasury is returned when an AccountPool without a treasury account is
+// asked to sweep its accounts.
+var ErrNoTreasury = errors.New("no treasury account")
+
+// ErrInvalidTopUpBalance is returned when an AccountPool is created with a
+// TopUpBalance that is not greater than its MinBalance.
+var ErrInvalidTopUpBalance = errors.New("top-up balance must be greater than the min balance")
+
+// AccountPoolOptions configure how an AccountPool funds its accounts.
+type AccountPoolOptions struct {
+
+	// MinBalance is the balance (in wei) below which an account is topped up
+	// by the treasury before it is used. A nil balance disables top-ups.
+	MinBalance *big.Int
+
+	// TopUpBalance is the balance (in wei) that an account is topped up to. It
+	// must be greater than the MinBalance. A nil balance tops accounts up to
+	// twice the MinBalance.
+	TopUpBalance *big.Int
+
+	// ConfirmBlocks is the number of blocks that top-ups and sweeps wait for.
+	ConfirmBlocks int64
+}
+
+// AccountPool spreads transactions across several accounts, so that their
+// throughput is not limited by the nonce sequence of a single account. Every
+// transaction is sent by the account with the fewest pending transactions.
+// Accounts are funded by a treasury account, and their funds are swept back to
+// the treasury when the pool is closed.
+type AccountPool interface {
+
+	// Accounts of the pool, excluding the treasury.
+	Accounts() []Account
+
+	// Treasury account that funds the accounts of the pool. It is nil if the
+	// pool has no treasury.
+	Treasury() Account
+
+	// Acquire returns the account with the fewest pending transactions, after
+	// topping it up if its balance is below the MinBalance. The account
+	// counts as pending until the release function is called.
+	Acquire(ctx context.Context) (Account, func(), error)
+
+	// Transact performs a write operation, as described by Account.Transact,
+	// using an acquired account.
+	Transact(ctx context.Context, preConditionCheck func() bool, f func(*bind.TransactOpts) (*types.Transaction, error), postConditionCheck func() bool, confirmBlocks int64) (*types.Transaction, error)
+
+	// TransactWithResult performs a write operation, as described by
+	// Account.TransactWithResult, using an acquired account.
+	TransactWithResult(ctx context.Context, preConditionCheck func() bool, f func(*bind.TransactOpts) (*types.Transaction, error), postConditionCheck func() bool, confirmBlocks int64) (*TransactResult, error)
+
+	// Sweep transfers the whole balance of every account of the pool to the
+	// treasury. Accounts whose balance cannot pay for the transfer are
+	// skipped. It returns the first error, after trying every account.
+	Sweep(ctx context.Context) error
+
+	// Close sweeps the accounts of the pool if it has a treasury, and closes
+	// the client of the pool if the pool created it.
+	Close(ctx context.Context) error
+
+	// SetLogger sets the Logger that receives the events of the pool and its
+	// accounts. Setting a nil logger discards all events.
+	SetLogger(logger Logger)
+}
+
+type poolAccount struct {
+	account Account
+	pending int
+
+	// topUpMu is held while the account is topped up, so that concurrent
+	// acquisitions do not top it up more than once
+	topUpMu *sync.Mutex
+}
+
+type accountPool struct {
+	mu       *sync.Mutex
+	accounts []*poolAccount
+	next     int
+
+	treasury Account
+	options  AccountPoolOptions
+	logger   Logger
+
+	// client is only set if the pool created the client of its accounts
+	client *Client
+}
+
+// NewAccountPool returns a pool of the accounts, funded by the treasury. The
+// treasury can be nil, in which case accounts are never topped up or swept.
+func NewAccountPool(treasury Account, accounts []Account, options AccountPoolOptions) (AccountPool, error) {
+	if len(accounts) == 0 {
+		return nil, ErrNoAccounts
+	}
+	if options.MinBalance != nil && options.TopUpBalance != nil && options.TopUpBalance.Cmp(options.MinBalance) <= 0 {
+		return nil, ErrInvalidTopUpBalance
+	}
+	pool := &accountPool{
+		mu:       new(sync.Mutex),
+		accounts: make([]*poolAccount, len(accounts)),
+		treasury: treasury,
+		options:  options,
+		logger:   NopLogger(),
+	}
+	for i, account := range accounts {
+		pool.accounts[i] = &poolAccount{account: account, topUpMu: new(sync.Mutex)}
+	}
+	return pool, nil
+}
+
+// NewAccountPoolFromMnemonic returns a pool of size accounts derived from the
+// BIP-39 mnemonic, which are connected to an Ethereum client. The treasury is
+// the account at m/44'/60'/0'/0/0, and the accounts of the pool are the
+// accounts at m/44'/60'/0'/0/1 to m/44'/60'/0'/0/size. The gas price of every
+// transaction is taken from the gasPriceOracle. If the oracle is nil, gas
+// prices are taken from ethGasStation.
+func NewAccountPoolFromMnemonic(url, mnemonic string, size int, gasPriceOracle GasPriceOracle, options AccountPoolOptions) (AccountPool, error) {
+	if size <= 0 {
+		return nil, ErrNoAccounts
+	}
+	seed, err := mnemonicSeed(mnemonic)
+	if err != nil {
+		return nil, err
+	}
+	defer zeroBytes(seed)
+
+	signers := make([]Signer, size+1)
+	for i := range signers {
+		path := append(accounts.DerivationPath{}, accounts.DefaultRootDerivationPath...)
+		path = append(path, uint32(i))
+		privateKey, err := deriveKey(seed, path)
+		if err != nil {
+			return nil, err
+		}
+		signers[i] = NewPrivateKeySigner(privateKey)
+	}
+	return newAccountPool(url, signers[0], signers[1:], gasPriceOracle, options)
+}
+
+// NewAccountPoolFromKeys returns a pool of accounts for the private keys, which
+// are connected to an Ethereum client and funded by the account of the
+// treasury key. The treasury key can be nil, in which case accounts are never
+// topped up or swept. The gas price of every transaction is taken from the
+// gasPriceOracle. If the oracle is nil, gas prices are taken from
+// ethGasStation.
+func NewAccountPoolFromKeys(url string, treasuryKey *ecdsa.PrivateKey, privateKeys []*ecdsa.PrivateKey, gasPriceOracle GasPriceOracle, options AccountPoolOptions) (AccountPool, error) {
+	var treasury Signer
+	if treasuryKey != nil {
+		treasury = NewPrivateKeySigner(treasuryKey)
+	}
+	signers := make([]Signer, len(privateKeys))
+	for i, privateKey := range privateKeys {
+		signers[i] = NewPrivateKeySigner(privateKey)
+	}
+	return newAccountPool(url, treasury, signers, gasPriceOracle, options)
+}
+
+// newAccountPool returns a pool of accounts for the signers, funded by the
+// account of the treasury signer, which can be nil. The accounts share a
+// single connection to an Ethereum client.
+func newAccountPool(url string, treasury Signer, signers []Signer, gasPriceOracle GasPriceOracle, options AccountPoolOptions) (AccountPool, error) {
+	if len(signers) == 0 {
+		return nil, ErrNoAccounts
+	}
+	if gasPriceOracle == nil {
+		gasPriceOracle = NewEthGasStationOracle()
+	}
+	client, err := Connect(url)
+	if err != nil {
+		return nil, err
+	}
+
+	var treasuryAccount Account
+	if treasury != nil {
+		if treasuryAccount, err = NewAccountWithSigner(client, treasury, gasPriceOracle); err != nil {
+			client.Close()
+			return nil, err
+		}
+	}
+	poolAccounts := make([]Account, len(signers))
+	for i, signer := range signers {
+		if poolAccounts[i], err = NewAccountWithSigner(client, signer, gasPriceOracle); err != nil {
+			client.Close()
+			return nil, err
+		}
+	}
+
+	pool, err := NewAccountPool(treasuryAccount, poolAccounts, options)
+	if err != nil {
+		client.Close()
+		return nil, err
+	}
+	pool.(*accountPool).client = &client
+	return pool, nil
+}
+
+func (pool *accountPool) Accounts() []Account {
+	accounts := make([]Account, len(pool.accounts))
+	for i, entry := range pool.accounts {
+		accounts[i] = entry.account
+	}
+	return accounts
+}
+
+func (pool *accountPool) Treasury() Account {
+	return pool.treasury
+}
+
+// Acquire returns the account with the fewest pending transactions. Ties are
+// broken in round robin order, so that idle accounts are used in turn.
+func (pool *accountPool) Acquire(ctx context.Context) (Account, func(), error) {
+	pool.mu.Lock()
+	var acquired *poolAccount
+	for i := range pool.accounts {
+		entry := pool.accounts[(pool.next+i)%len(pool.accounts)]
+		if acquired == nil || entry.pending < acquired.pending {
+			acquired = entry
+		}
+	}
+	acquired.pending++
+	pool.next = (pool.next + 1) % len(pool.accounts)
+	pool.mu.Unlock()
+
+	var once sync.Once
+	release := func() {
+		once.Do(func() {
+			pool.mu.Lock()
+			defer pool.mu.Unlock()
+			acquired.pending--
+		})
+	}
+	if err := pool.topUp(ctx, acquired); err != nil {
+		release()
+		return nil, nil, err
+	}
+	return acquired.account, release, nil
+}
+
+func (pool *accountPool) Transact(ctx context.Context, preConditionCheck func() bool, f func(*bind.TransactOpts) (*types.Transaction, error), postConditionCheck func() bool, confirmBlocks int64) (*types.Transaction, error) {
+	account, release, err := pool.Acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	return account.Transact(ctx, preConditionCheck, f, postConditionCheck, confirmBlocks)
+}
+
+func (pool *accountPool) TransactWithResult(ctx context.Context, preConditionCheck func() bool, f func(*bind.TransactOpts) (*types.Transaction, error), postConditionCheck func() bool, confirmBlocks int64) (*TransactResult, error) {
+	account, release, err := pool.Acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	return account.TransactWithResult(ctx, preConditionCheck, f, postConditionCheck, confirmBlocks)
+}
+
+// topUp transfers funds from the treasury to the account if its balance is
+// below the MinBalance.
+func (pool *accountPool) topUp(ctx context.Context, entry *poolAccount) error {
+	if pool.treasury == nil || pool.options.MinBalance == nil {
+		return nil
+	}
+	entry.topUpMu.Lock()
+	defer entry.topUpMu.Unlock()
+
+	balance, err := entry.account.BalanceAt(WithBlock(ctx, LatestBlock), nil)
+	if err != nil {
+		return err
+	}
+	if balance.Cmp(pool.options.MinBalance) >= 0 {
+		return nil
+	}
+	target := pool.options.TopUpBalance
+	if target == nil {
+		target = new(big.Int).Mul(pool.options.MinBalance, big.NewInt(2))
+	}
+	amount := new(big.Int).Sub(target, balance)
+	if amount.Sign() <= 0 {
+		return nil
+	}
+	pool.log().Info("topping up account", "address", entry.account.Address(), "balance", balance, "amount", amount)
+	_, err = pool.treasury.Transfer(ctx, entry.account.Address(), amount, nil, pool.options.ConfirmBlocks, false)
+	return err
+}
+
+func (pool *accountPool) Sweep(ctx context.Context) error {
+	if pool.treasury == nil {
+		return ErrNoTreasury
+	}
+	var sweepErr error
+	for _, entry := range pool.accounts {
+		if err := pool.sweep(ctx, entry.account); err != nil {
+			pool.log().Warn("cannot sweep account", "address", entry.account.Address(), "err", err)
+			if sweepErr == nil {
+				sweepErr = err
+			}
+		}
+	}
+	return sweepErr
+}
+
+// sweep transfers the whole balance of the account to the treasury, unless its
+// balance cannot pay for the transfer at the suggested gas price.
+func (pool *accountPool) sweep(ctx context.Context, account Account) error {
+	balance, err := account.BalanceAt(WithBlock(ctx, LatestBlock), nil)
+	if err != nil {
+		return err
+	}
+	gasPrice, err := account.Backend().SuggestGasPrice(ctx)
+	if err != nil {
+		return err
+	}
+	if balance.Cmp(new(big.Int).Mul(big.NewInt(21000), gasPrice)) <= 0 {
+		return nil
+	}
+	pool.log().Info("sweeping account", "address", account.Address(), "balance", balance)
+	_, err = account.Transfer(ctx, pool.treasury.Address(), nil, gasPrice, pool.options.ConfirmBlocks, true)
+	return err
+}
+
+func (pool *accountPool) Close(ctx context.Context) error {
+	var err error
+	if pool.treasury != nil {
+		err = pool.Sweep(ctx)
+	}
+	if pool.client != nil {
+		pool.client.Close()
+	}
+	return err
+}
+
+func (pool *accountPool) SetLogger(logger Logger) {
+	if logger == nil {
+		logger = NopLogger()
+	}
+	pool.mu.Lock()
+	pool.logger = logger
+	pool.mu.Unlock()
+
+	if pool.treasury != nil {
+		pool.treasury.SetLogger(logger)
+	}
+	for _, entry := range pool.accounts {
+		entry.account.SetLogger(logger)
+	}
+}
+
+// log returns the Logger of the pool.
+func (pool *accountPool) log() Logger {
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+
+	return pool.logger
+}
@@ -0,0 +1,170 @@
+package beth_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"math/big"
+	"sync"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/republicprotocol/beth-go"
+)
+
+var _ = Describe("account pools", func() {
+
+	var chain *simulatedChain
+	var pool beth.AccountPool
+
+	oneEther := big.NewInt(1e18)
+
+	BeforeEach(func() {
+		chain = newSimulatedChain(1)
+
+		keys := make([]*ecdsa.PrivateKey, 3)
+		for i := range keys {
+			var err error
+			keys[i], err = crypto.GenerateKey()
+			Expect(err).ShouldNot(HaveOccurred())
+		}
+		var err error
+		pool, err = beth.NewAccountPoolFromKeys(chain.url(), chain.keys[0], keys, beth.NewFixedGasPriceOracle(big.NewInt(10e9)), beth.AccountPoolOptions{
+			MinBalance:   oneEther,
+			TopUpBalance: new(big.Int).Mul(oneEther, big.NewInt(2)),
+		})
+		Expect(err).ShouldNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+
+		Expect(pool.Close(ctx)).Should(Succeed())
+		chain.close()
+	})
+
+	// transfer returns a function that transfers wei to the address, for use
+	// with Transact.
+	transfer := func(to common.Address, value *big.Int) func(*bind.TransactOpts) (*types.Transaction, error) {
+		return func(txOpts *bind.TransactOpts) (*types.Transaction, error) {
+			bound := bind.NewBoundContract(to, abi.ABI{}, nil, pool.Treasury().Backend(), nil)
+			txOpts.Value = value
+			txOpts.GasLimit = 21000
+			return bound.Transfer(txOpts)
+		}
+	}
+
+	Context("when acquiring accounts", func() {
+		It("should acquire the accounts with the fewest pending transactions", func() {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+			defer cancel()
+
+			acquired := map[common.Address]bool{}
+			releases := []func(){}
+			for range pool.Accounts() {
+				account, release, err := pool.Acquire(ctx)
+				Expect(err).ShouldNot(HaveOccurred())
+				acquired[account.Address()] = true
+				releases = append(releases, release)
+			}
+			Expect(acquired).Should(HaveLen(len(pool.Accounts())))
+
+			// Once an account is released, it is acquired next
+			releases[1]()
+			account, release, err := pool.Acquire(ctx)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(account.Address()).Should(Equal(pool.Accounts()[1].Address()))
+			release()
+			for _, release := range releases {
+				release()
+			}
+		})
+
+		It("should top up accounts from the treasury", func() {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+			defer cancel()
+
+			account, release, err := pool.Acquire(ctx)
+			Expect(err).ShouldNot(HaveOccurred())
+			defer release()
+			balance, err := account.BalanceAt(ctx, nil)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(balance).Should(Equal(new(big.Int).Mul(oneEther, big.NewInt(2))))
+		})
+	})
+
+	Context("when the top-up balance is not greater than the min balance", func() {
+		It("should not create the pool", func() {
+			key, err := crypto.GenerateKey()
+			Expect(err).ShouldNot(HaveOccurred())
+			_, err = beth.NewAccountPoolFromKeys(chain.url(), chain.keys[0], []*ecdsa.PrivateKey{key}, beth.NewFixedGasPriceOracle(big.NewInt(10e9)), beth.AccountPoolOptions{
+				MinBalance:   oneEther,
+				TopUpBalance: oneEther,
+			})
+			Expect(err).Should(Equal(beth.ErrInvalidTopUpBalance))
+		})
+	})
+
+	Context("when transacting", func() {
+		It("should spread transactions across the accounts and sweep them", func() {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+			defer cancel()
+
+			to := common.HexToAddress("0x000000000000000000000000000000000000dEaD")
+			value := big.NewInt(1e15)
+			var wg sync.WaitGroup
+			errs := make([]error, 6)
+			for i := range errs {
+				wg.Add(1)
+				go func(i int) {
+					defer wg.Done()
+					_, errs[i] = pool.Transact(ctx, nil, transfer(to, value), nil, 0)
+				}(i)
+			}
+			wg.Wait()
+			for _, err := range errs {
+				Expect(err).ShouldNot(HaveOccurred())
+			}
+
+			client := pool.Treasury().Client()
+			balance, err := client.BalanceOf(ctx, to)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(balance).Should(Equal(new(big.Int).Mul(value, big.NewInt(6))))
+			for _, account := range pool.Accounts() {
+				nonce, err := account.Backend().NonceAt(ctx, account.Address(), nil)
+				Expect(err).ShouldNot(HaveOccurred())
+				Expect(nonce).Should(BeNumerically(">", 0))
+			}
+
+			// Sweeping the pool transfers the balances of the accounts back to
+			// the treasury
+			Expect(pool.Sweep(ctx)).Should(Succeed())
+			for _, account := range pool.Accounts() {
+				balance, err := client.BalanceOf(ctx, account.Address())
+				Expect(err).ShouldNot(HaveOccurred())
+				Expect(balance.Cmp(oneEther)).Should(BeNumerically("<", 0))
+			}
+			balance, err = client.BalanceOf(ctx, pool.Treasury().Address())
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(balance.Cmp(new(big.Int).Sub(simulatedChainFunds, oneEther))).Should(BeNumerically(">", 0))
+		})
+	})
+
+	Context("when the pool is created from a mnemonic", func() {
+		It("should derive the treasury and the accounts", func() {
+			pool, err := beth.NewAccountPoolFromMnemonic(chain.url(), testMnemonic, 2, beth.NewFixedGasPriceOracle(big.NewInt(10e9)), beth.AccountPoolOptions{})
+			Expect(err).ShouldNot(HaveOccurred())
+			defer pool.Close(context.Background())
+			Expect(pool.Treasury().Address()).Should(Equal(common.HexToAddress("0xf39Fd6e51aad88F6F4ce6aB8827279cffFb92266")))
+			Expect(pool.Accounts()).Should(HaveLen(2))
+			Expect(pool.Accounts()[0].Address()).Should(Equal(common.HexToAddress("0x70997970C51812dc3A010C7d01b50e0d17dc79C8")))
+		})
+	})
+})
@@ -0,0 +1,299 @@
+package beth
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// Quorum defines how many endpoints a read is sent to, and how many of them
+// must return the same answer before it is trusted.
+type Quorum struct {
+
+	// Size is the number of endpoints that a read is sent to. It is capped by
+	// the number of endpoints of the client.
+	Size int
+
+	// Threshold is the number of endpoints that must return the same answer.
+	// A zero threshold requires a majority of the endpoints that a read is
+	// sent to.
+	Threshold int
+}
+
+// threshold returns the number of answers that must agree when a read is sent
+// to the given number of endpoints.
+func (quorum Quorum) threshold(endpoints int) int {
+	if quorum.Threshold > 0 {
+		return quorum.Threshold
+	}
+	return endpoints/2 + 1
+}
+
+// QuorumAnswer is the answer of a single endpoint to a quorum read.
+type QuorumAnswer struct {
+	URL string
+
+	// Result returned by the endpoint, as JSON. It is empty if the endpoint
+	// returned an error.
+	Result string
+
+	// Err returned by the endpoint, or by the request to the endpoint.
+	Err error
+}
+
+// ErrQuorumDisagreement is returned when not enough endpoints return the same
+// answer to a quorum read.
+type ErrQuorumDisagreement struct {
+	Method string
+
+	// BlockNumber at which the read was pinned. It is nil if the read does
+	// not depend on a block.
+	BlockNumber *uint64
+
+	Threshold int
+	Answers   []QuorumAnswer
+}
+
+// Error implements the error interface.
+func (err *ErrQuorumDisagreement) Error() string {
+	answers := make([]string, len(err.Answers))
+	for i, answer := range err.Answers {
+		if answer.Err != nil {
+			answers[i] = fmt.Sprintf("%s: error %v", answer.URL, answer.Err)
+		} else {
+			answers[i] = fmt.Sprintf("%s: %s", answer.URL, answer.Result)
+		}
+	}
+	at := ""
+	if err.BlockNumber != nil {
+		at = fmt.Sprintf(" at block %v", *err.BlockNumber)
+	}
+	return fmt.Sprintf("quorum disagreement on %s%s: fewer than %v of %v answers agree [%s]", err.Method, at, err.Threshold, len(err.Answers), strings.Join(answers, "; "))
+}
+
+// quorumMethods are the JSON-RPC reads that can be made with a quorum, mapped
+// to the position of their block parameter, or -1 if they do not have one.
+var quorumMethods = map[string]int{
+	"eth_getBalance":            1,
+	"eth_getCode":               1,
+	"eth_getTransactionCount":   1,
+	"eth_getStorageAt":          2,
+	"eth_call":                  1,
+	"eth_getBlockByNumber":      0,
+	"eth_getBlockByHash":        -1,
+	"eth_getTransactionByHash":  -1,
+	"eth_getTransactionReceipt": -1,
+	"eth_chainId":               -1,
+	"net_version":               -1,
+}
+
+// quorumKey is the context key of a Quorum.
+type quorumKey struct{}
+
+// WithQuorum returns a copy of the context that carries the quorum. Reads made
+// with the context by a client connected using ConnectEndpoints use the quorum
+// instead of the quorum of the client.
+func WithQuorum(ctx context.Context, quorum Quorum) context.Context {
+	return context.WithValue(ctx, quorumKey{}, quorum)
+}
+
+// SetQuorum sets the Quorum used by the reads of a client connected using
+// ConnectEndpoints, including reads made through its ethclient and through
+// Get. Reads that depend on the latest block are pinned to a block that
+// enough endpoints have seen. Setting a nil quorum sends every read to a
+// single endpoint. The quorum has no effect on a client with a single
+// endpoint, or on batched reads such as BalancesOf, which are always sent to
+// a single endpoint.
+func (client *Client) SetQuorum(quorum *Quorum) {
+	if client.endpoints == nil {
+		return
+	}
+	client.endpoints.mu.Lock()
+	defer client.endpoints.mu.Unlock()
+
+	client.endpoints.defaultQuorum = quorum
+}
+
+// quorum returns the quorum carried by the context, or the quorum of the
+// pool. It returns false if neither exists.
+func (pool *endpointPool) quorum(ctx context.Context) (Quorum, bool) {
+	if quorum, ok := ctx.Value(quorumKey{}).(Quorum); ok {
+		return quorum, quorum.Size > 1
+	}
+
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+
+	if pool.defaultQuorum == nil {
+		return Quorum{}, false
+	}
+	return *pool.defaultQuorum, pool.defaultQuorum.Size > 1
+}
+
+// roundTripQuorum sends a read to several endpoints, and returns the response
+// of an endpoint once enough endpoints agree with it. It returns false if the
+// request is not a read that can be made with a quorum.
+func (pool *endpointPool) roundTripQuorum(req *http.Request, body []byte, quorum Quorum) (*http.Response, bool, error) {
+	var request struct {
+		JSONRPC string            `json:"jsonrpc"`
+		ID      json.RawMessage   `json:"id"`
+		Method  string            `json:"method"`
+		Params  []json.RawMessage `json:"params"`
+	}
+	if err := json.Unmarshal(body, &request); err != nil {
+		// Batches are not made with a quorum
+		return nil, false, nil
+	}
+	blockParam, ok := quorumMethods[request.Method]
+	if !ok {
+		return nil, false, nil
+	}
+
+	endpoints := pool.order()
+	if len(endpoints) > quorum.Size {
+		endpoints = endpoints[:quorum.Size]
+	}
+	disagreement := &ErrQuorumDisagreement{
+		Method:    request.Method,
+		Threshold: quorum.threshold(len(endpoints)),
+		Answers:   make([]QuorumAnswer, len(endpoints)),
+	}
+	for i, endpoint := range endpoints {
+		disagreement.Answers[i].URL = endpoint.url.Redacted()
+	}
+
+	// Pin reads of the latest block to the highest block that enough
+	// endpoints have seen, so that endpoints do not disagree only because
+	// some of them are ahead of others
+	if blockParam >= 0 && blockParam <= len(request.Params) {
+		var tag string
+		if blockParam == len(request.Params) || json.Unmarshal(request.Params[blockParam], &tag) == nil && tag == "latest" {
+			blockNumber, err := pool.pinnedBlockNumber(req.Context(), endpoints, disagreement.Threshold)
+			if err != nil {
+				return nil, true, err
+			}
+			disagreement.BlockNumber = &blockNumber
+			pinned, err := json.Marshal(hexutil.Uint64(blockNumber))
+			if err != nil {
+				return nil, true, err
+			}
+			if blockParam == len(request.Params) {
+				request.Params = append(request.Params, pinned)
+			} else {
+				request.Params[blockParam] = pinned
+			}
+			if body, err = json.Marshal(request); err != nil {
+				return nil, true, err
+			}
+		}
+	}
+
+	// Send the read to every endpoint at once
+	responses := make([]*http.Response, len(endpoints))
+	keys := make([]string, len(endpoints))
+	var wg sync.WaitGroup
+	for i := range endpoints {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			resp, respBody, key, answer := pool.sendQuorum(req, endpoints[i], body)
+			if resp != nil {
+				resp.Body = ioutil.NopCloser(bytes.NewReader(respBody))
+			}
+			responses[i], keys[i] = resp, key
+			disagreement.Answers[i].Result = answer.Result
+			disagreement.Answers[i].Err = answer.Err
+		}(i)
+	}
+	wg.Wait()
+	if err := req.Context().Err(); err != nil {
+		return nil, true, err
+	}
+
+	counts := map[string]int{}
+	for i, key := range keys {
+		if responses[i] == nil {
+			continue
+		}
+		if counts[key]++; counts[key] >= disagreement.Threshold {
+			return responses[i], true, nil
+		}
+	}
+	return nil, true, disagreement
+}
+
+// sendQuorum sends a quorum read to a single endpoint. It returns the response
+// and its body, and a key that is equal for equal answers. The response is nil
+// if the endpoint did not answer.
+func (pool *endpointPool) sendQuorum(req *http.Request, endpoint *endpoint, body []byte) (*http.Response, []byte, string, QuorumAnswer) {
+	resp, err := pool.send(req, endpoint, body)
+	if err != nil {
+		return nil, nil, "", QuorumAnswer{Err: err}
+	}
+	defer resp.Body.Close()
+	respBody, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, nil, "", QuorumAnswer{Err: err}
+	}
+
+	var response struct {
+		Result json.RawMessage `json:"result"`
+		Error  *RPCError       `json:"error"`
+	}
+	if err := json.Unmarshal(respBody, &response); err != nil {
+		return nil, nil, "", QuorumAnswer{Err: err}
+	}
+	if response.Error != nil {
+		return resp, respBody, "error:" + response.Error.Error(), QuorumAnswer{Err: response.Error}
+	}
+	result := new(bytes.Buffer)
+	if err := json.Compact(result, response.Result); err != nil {
+		return nil, nil, "", QuorumAnswer{Err: err}
+	}
+	return resp, respBody, "result:" + result.String(), QuorumAnswer{Result: result.String()}
+}
+
+// pinnedBlockNumber returns the highest block number that at least threshold
+// of the endpoints have seen.
+func (pool *endpointPool) pinnedBlockNumber(ctx context.Context, endpoints []*endpoint, threshold int) (uint64, error) {
+	blockNumbers := make([]uint64, len(endpoints))
+	errs := make([]error, len(endpoints))
+	var wg sync.WaitGroup
+	for i := range endpoints {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			blockNumbers[i], errs[i] = pool.blockNumber(ctx, endpoints[i].url)
+		}(i)
+	}
+	wg.Wait()
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	seen := make([]uint64, 0, len(endpoints))
+	answers := make([]QuorumAnswer, len(endpoints))
+	for i, endpoint := range endpoints {
+		answers[i] = QuorumAnswer{URL: endpoint.url.Redacted(), Result: fmt.Sprintf("%v", blockNumbers[i]), Err: errs[i]}
+		if errs[i] == nil {
+			seen = append(seen, blockNumbers[i])
+		} else {
+			answers[i].Result = ""
+		}
+	}
+	if threshold < 1 || len(seen) < threshold {
+		return 0, &ErrQuorumDisagreement{Method: "eth_blockNumber", Threshold: threshold, Answers: answers}
+	}
+	sort.Slice(seen, func(i, j int) bool {
+		return seen[i] > seen[j]
+	})
+	return seen[threshold-1], nil
+}
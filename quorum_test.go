@@ -0,0 +1,147 @@
+package beth_test
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"net/http/httptest"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/republicprotocol/beth-go"
+)
+
+var _ = Describe("quorum reads", func() {
+
+	var chains []*fakeChain
+	var servers []*httptest.Server
+	var client beth.Client
+
+	connect := func(heads []uint64, balances []int64) {
+		chains, servers = nil, nil
+		urls := []string{}
+		for i := range heads {
+			chain := newFakeChain(heads[i])
+			chain.balance = big.NewInt(balances[i])
+			server := httptest.NewServer(chain)
+			chains = append(chains, chain)
+			servers = append(servers, server)
+			urls = append(urls, server.URL)
+		}
+
+		options := beth.DefaultEndpointOptions()
+		options.HealthCheckInterval = 0
+		var err error
+		client, err = beth.ConnectEndpoints(urls, options)
+		Expect(err).ShouldNot(HaveOccurred())
+		client.SetQuorum(&beth.Quorum{Size: 3, Threshold: 2})
+	}
+
+	AfterEach(func() {
+		client.Close()
+		for _, server := range servers {
+			server.Close()
+		}
+	})
+
+	Context("when enough endpoints agree", func() {
+		It("should return the value that they agree on", func() {
+			connect([]uint64{100, 100, 100}, []int64{7, 42, 42})
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			balance, err := client.BalanceOf(ctx, common.Address{})
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(balance.Int64()).Should(Equal(int64(42)))
+		})
+	})
+
+	Context("when the endpoints are at different blocks", func() {
+		It("should pin the read to a block that enough endpoints have seen", func() {
+			connect([]uint64{100, 100, 200}, []int64{42, 42, 42})
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			_, err := client.EthClient().BalanceAt(ctx, common.Address{}, nil)
+			Expect(err).ShouldNot(HaveOccurred())
+
+			for _, chain := range chains {
+				chain.mu.Lock()
+				Expect(chain.balanceBlocks).Should(HaveLen(1))
+				block, err := hexutil.DecodeUint64(chain.balanceBlocks[0])
+				chain.mu.Unlock()
+				Expect(err).ShouldNot(HaveOccurred())
+				Expect(block).Should(BeNumerically("<", 200))
+			}
+		})
+	})
+
+	Context("when not enough endpoints agree", func() {
+		It("should return the divergent answers", func() {
+			connect([]uint64{100, 100, 100}, []int64{1, 2, 3})
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			_, err := client.EthClient().BalanceAt(ctx, common.Address{}, nil)
+
+			var disagreement *beth.ErrQuorumDisagreement
+			Expect(errors.As(err, &disagreement)).Should(BeTrue())
+			Expect(disagreement.Method).Should(Equal("eth_getBalance"))
+			Expect(disagreement.BlockNumber).ShouldNot(BeNil())
+			Expect(disagreement.Answers).Should(HaveLen(3))
+			results := []string{}
+			for _, answer := range disagreement.Answers {
+				Expect(answer.Err).ShouldNot(HaveOccurred())
+				results = append(results, answer.Result)
+			}
+			Expect(results).Should(ConsistOf(`"0x1"`, `"0x2"`, `"0x3"`))
+		})
+	})
+
+	Context("when a call carries a quorum", func() {
+		It("should override the quorum of the client", func() {
+			connect([]uint64{100, 100, 100}, []int64{1, 2, 3})
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			ctx = beth.WithQuorum(ctx, beth.Quorum{Size: 1})
+			balance, err := client.EthClient().BalanceAt(ctx, common.Address{}, nil)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(balance.Int64()).Should(Equal(int64(1)))
+		})
+	})
+
+	Context("when the size is larger than the number of endpoints", func() {
+		It("should require a majority of the endpoints", func() {
+			connect([]uint64{100, 100}, []int64{42, 42})
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			ctx = beth.WithQuorum(ctx, beth.Quorum{Size: 5})
+			balance, err := client.EthClient().BalanceAt(ctx, common.Address{}, nil)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(balance.Int64()).Should(Equal(int64(42)))
+		})
+	})
+
+	Context("when balances are read in batches", func() {
+		It("should read every batch from a single endpoint", func() {
+			connect([]uint64{100, 100, 100}, []int64{1, 2, 3})
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			balances, err := client.BalancesOf(ctx, []common.Address{{}, {1}})
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(balances).Should(Equal([]*big.Int{big.NewInt(1), big.NewInt(1)}))
+			for _, chain := range chains[1:] {
+				chain.mu.Lock()
+				Expect(chain.balanceBlocks).Should(BeEmpty())
+				chain.mu.Unlock()
+			}
+		})
+	})
+})
@@ -0,0 +1,214 @@
+package beth
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// ReplacementPolicy defines how a transaction that has not been mined is
+// replaced. A replacement re-uses the nonce of the stuck transaction, so at
+// most one of the transactions can be mined. Transact keeps replacing the
+// transaction until one of them is mined, or until its context is done.
+type ReplacementPolicy struct {
+
+	// Interval that a transaction is given to be mined before it is replaced.
+	// A zero interval uses the interval of the DefaultReplacementPolicy.
+	Interval time.Duration
+
+	// BumpPercent is the percentage by which the gas price (or the fee cap
+	// and tip cap of a dynamic-fee transaction) is increased with every
+	// replacement. Most nodes reject replacements that are bumped by less
+	// than 10%, so a bump below MinReplacementBumpPercent is raised to it, and
+	// a zero bump uses the bump of the DefaultReplacementPolicy.
+	BumpPercent float64
+
+	// MaxGasPrice caps the gas price (or the fee cap of a dynamic-fee
+	// transaction) of a replacement. It can be nil, in which case the gas
+	// price is not capped.
+	MaxGasPrice *big.Int
+}
+
+// MinReplacementBumpPercent is the lowest percentage by which the gas price of
+// a replacement is increased. Nodes reject replacements that are bumped by
+// less than 10% by default.
+const MinReplacementBumpPercent = 10.0
+
+// DefaultReplacementPolicy replaces a transaction that has not been mined
+// within 3 minutes, bumping its gas price by 12.5%.
+func DefaultReplacementPolicy() *ReplacementPolicy {
+	return &ReplacementPolicy{
+		Interval:    3 * time.Minute,
+		BumpPercent: 12.5,
+	}
+}
+
+// withDefaults returns a copy of the policy in which zero fields are replaced
+// by those of the DefaultReplacementPolicy, and the bump is at least the
+// MinReplacementBumpPercent.
+func (policy ReplacementPolicy) withDefaults() ReplacementPolicy {
+	defaults := DefaultReplacementPolicy()
+	if policy.Interval <= 0 {
+		policy.Interval = defaults.Interval
+	}
+	if policy.BumpPercent == 0 {
+		policy.BumpPercent = defaults.BumpPercent
+	}
+	if policy.BumpPercent < MinReplacementBumpPercent {
+		policy.BumpPercent = MinReplacementBumpPercent
+	}
+	return policy
+}
+
+// bumpGasPrice returns the gas price increased by the bump percentage of the
+// policy and capped by its max gas price. It returns false if the gas price
+// cannot be increased any further.
+func (policy ReplacementPolicy) bumpGasPrice(gasPrice *big.Int) (*big.Int, bool) {
+	bumped := new(big.Int).Mul(gasPrice, big.NewInt(10000+int64(policy.BumpPercent*100)))
+	bumped.Div(bumped, big.NewInt(10000))
+	if bumped.Cmp(gasPrice) <= 0 {
+		bumped.Add(gasPrice, big.NewInt(1))
+	}
+	if policy.MaxGasPrice != nil && bumped.Cmp(policy.MaxGasPrice) > 0 {
+		if gasPrice.Cmp(policy.MaxGasPrice) >= 0 {
+			return gasPrice, false
+		}
+		bumped.Set(policy.MaxGasPrice)
+	}
+	return bumped, true
+}
+
+// bump returns the transaction data of a replacement for the transaction. It
+// returns false if the gas price of the transaction cannot be increased any
+// further.
+func (policy ReplacementPolicy) bump(tx *types.Transaction) (types.TxData, bool) {
+	switch tx.Type() {
+	case types.DynamicFeeTxType:
+		gasFeeCap, ok := policy.bumpGasPrice(tx.GasFeeCap())
+		if !ok {
+			return nil, false
+		}
+		gasTipCap, _ := policy.bumpGasPrice(tx.GasTipCap())
+		if gasTipCap.Cmp(gasFeeCap) > 0 {
+			gasTipCap = gasFeeCap
+		}
+		return &types.DynamicFeeTx{
+			ChainID:    tx.ChainId(),
+			Nonce:      tx.Nonce(),
+			GasTipCap:  gasTipCap,
+			GasFeeCap:  gasFeeCap,
+			Gas:        tx.Gas(),
+			To:         tx.To(),
+			Value:      tx.Value(),
+			Data:       tx.Data(),
+			AccessList: tx.AccessList(),
+		}, true
+
+	case types.AccessListTxType:
+		gasPrice, ok := policy.bumpGasPrice(tx.GasPrice())
+		if !ok {
+			return nil, false
+		}
+		return &types.AccessListTx{
+			ChainID:    tx.ChainId(),
+			Nonce:      tx.Nonce(),
+			GasPrice:   gasPrice,
+			Gas:        tx.Gas(),
+			To:         tx.To(),
+			Value:      tx.Value(),
+			Data:       tx.Data(),
+			AccessList: tx.AccessList(),
+		}, true
+
+	default:
+		gasPrice, ok := policy.bumpGasPrice(tx.GasPrice())
+		if !ok {
+			return nil, false
+		}
+		return &types.LegacyTx{
+			Nonce:    tx.Nonce(),
+			GasPrice: gasPrice,
+			Gas:      tx.Gas(),
+			To:       tx.To(),
+			Value:    tx.Value(),
+			Data:     tx.Data(),
+		}, true
+	}
+}
+
+// waitMined waits for tx to be mined on the blockchain. If the account has a
+// replacement policy, the transaction is replaced whenever it has not been
+// mined within the interval of the policy. It returns the receipt and the
+// transaction that was mined, along with every transaction that was sent,
+// starting with tx and followed by its replacements. It stops waiting when the
+// context is canceled.
+func (account *account) waitMined(ctx context.Context, tx *types.Transaction) (*types.Receipt, *types.Transaction, []*types.Transaction, error) {
+	account.mu.RLock()
+	replacementPolicy := account.replacementPolicy
+	account.mu.RUnlock()
+
+	sent := []*types.Transaction{tx}
+	if replacementPolicy == nil {
+		receipt, err := account.client.WaitMined(ctx, tx)
+		return receipt, tx, sent, err
+	}
+	policy := replacementPolicy.withDefaults()
+
+	replaceAt := time.Now().Add(policy.Interval)
+	for {
+		// Any of the transactions that have been sent can be mined, but only
+		// one of them can be mined because they all share a nonce
+		for _, sentTx := range sent {
+			receipt, err := account.client.Backend().TransactionReceipt(ctx, sentTx.Hash())
+			if err == nil && receipt != nil {
+				return receipt, sentTx, sent, nil
+			}
+		}
+
+		if !time.Now().Before(replaceAt) {
+			replaceAt = time.Now().Add(policy.Interval)
+			last := sent[len(sent)-1]
+			replacement, err := account.replace(ctx, policy, last)
+			switch {
+			case err != nil:
+				account.log().Warn("cannot replace transaction", "hash", last.Hash(), "nonce", last.Nonce(), "err", err)
+			case replacement == nil:
+				account.log().Debug("cannot bump gas price of transaction any further", "hash", last.Hash(), "nonce", last.Nonce())
+			default:
+				account.log().Info("transaction replaced", "hash", replacement.Hash(), "replaces", last.Hash(), "nonce", replacement.Nonce(), "gasPrice", replacement.GasPrice(), "gasFeeCap", replacement.GasFeeCap(), "gasTipCap", replacement.GasTipCap())
+				account.getMetrics().replacement()
+				sent = append(sent, replacement)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, sent[len(sent)-1], sent, ctx.Err()
+		case <-time.After(time.Second):
+		}
+	}
+}
+
+// replace signs and sends a replacement for the transaction. It returns a nil
+// transaction if the gas price of the transaction cannot be increased any
+// further.
+func (account *account) replace(ctx context.Context, policy ReplacementPolicy, tx *types.Transaction) (*types.Transaction, error) {
+	txData, ok := policy.bump(tx)
+	if !ok {
+		return nil, nil
+	}
+	replacement, err := account.signer.SignTx(ctx, types.NewTx(txData), account.chainID)
+	if err != nil {
+		return nil, err
+	}
+	if err := account.client.Backend().SendTransaction(ctx, replacement); err != nil {
+		// A replacement that the node already knows has been sent before
+		if err = ClassifyError(err); !errors.Is(err, ErrAlreadyKnown) {
+			return nil, err
+		}
+	}
+	return replacement, nil
+}
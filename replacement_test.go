@@ -0,0 +1,138 @@
+package beth_test
+
+import (
+	"context"
+	"math/big"
+	"net/http/httptest"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/republicprotocol/beth-go"
+)
+
+var _ = Describe("transaction replacements", func() {
+
+	var chain *fakeChain
+	var server *httptest.Server
+	var account beth.Account
+
+	BeforeEach(func() {
+		chain = newFakeChain(100)
+		server = httptest.NewServer(chain)
+
+		key, err := crypto.GenerateKey()
+		Expect(err).ShouldNot(HaveOccurred())
+		account, err = beth.NewAccount(server.URL, key, beth.NewFixedGasPriceOracle(big.NewInt(10e9)))
+		Expect(err).ShouldNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	// send signs and sends an empty transaction, for use with Transact. The
+	// nonces that it is called with are recorded.
+	send := func(nonces *[]uint64) func(*bind.TransactOpts) (*types.Transaction, error) {
+		return func(txOpts *bind.TransactOpts) (*types.Transaction, error) {
+			*nonces = append(*nonces, txOpts.Nonce.Uint64())
+			tx := types.NewTransaction(txOpts.Nonce.Uint64(), common.Address{}, big.NewInt(0), 21000, txOpts.GasPrice, nil)
+			tx, err := txOpts.Signer(txOpts.From, tx)
+			if err != nil {
+				return nil, err
+			}
+			return tx, account.Backend().SendTransaction(txOpts.Context, tx)
+		}
+	}
+
+	Context("when a transaction is not mined in time", func() {
+		It("should replace it with a transaction that has a bumped gas price", func() {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+			defer cancel()
+
+			// Only the replacement is mined. The policy has no bump, so the
+			// default bump is used.
+			chain.onSend = func(chain *fakeChain, tx *types.Transaction) {
+				if len(chain.sent) == 2 {
+					chain.mine(tx, chain.head)
+				}
+			}
+			account.SetReplacementPolicy(&beth.ReplacementPolicy{Interval: 100 * time.Millisecond})
+
+			nonces := []uint64{}
+			result, err := account.TransactWithResult(ctx, nil, send(&nonces), nil, 0)
+			Expect(err).ShouldNot(HaveOccurred())
+
+			chain.mu.Lock()
+			defer chain.mu.Unlock()
+			Expect(chain.sent).Should(HaveLen(2))
+			Expect(result.Attempts).Should(Equal([]beth.TransactAttempt{{Hash: chain.sent[0]}}))
+			Expect(result.Replacements).Should(Equal([]common.Hash{chain.sent[1]}))
+			Expect(result.Transaction.Hash()).Should(Equal(chain.sent[1]))
+			Expect(result.Transaction.Nonce()).Should(Equal(nonces[0]))
+			Expect(result.Transaction.GasPrice()).Should(Equal(big.NewInt(11.25e9)))
+		})
+
+		It("should raise a bump that nodes would reject", func() {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+			defer cancel()
+
+			chain.onSend = func(chain *fakeChain, tx *types.Transaction) {
+				if len(chain.sent) == 2 {
+					chain.mine(tx, chain.head)
+				}
+			}
+			account.SetReplacementPolicy(&beth.ReplacementPolicy{Interval: 100 * time.Millisecond, BumpPercent: 1})
+
+			nonces := []uint64{}
+			result, err := account.TransactWithResult(ctx, nil, send(&nonces), nil, 0)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(result.Transaction.GasPrice()).Should(Equal(big.NewInt(11e9)))
+		})
+
+		It("should not re-use the nonce while a replacement is known to the node", func() {
+			// The original transaction is dropped once it is replaced, but
+			// the replacement is never mined
+			chain.onSend = func(chain *fakeChain, tx *types.Transaction) {
+				if len(chain.sent) == 2 {
+					delete(chain.pool, chain.sent[0])
+				}
+			}
+			account.SetReplacementPolicy(&beth.ReplacementPolicy{Interval: 100 * time.Millisecond})
+
+			nonces := []uint64{}
+			for i := 0; i < 2; i++ {
+				ctx, cancel := context.WithTimeout(context.Background(), 2500*time.Millisecond)
+				_, err := account.TransactWithResult(ctx, nil, send(&nonces), func() bool { return false }, 0)
+				cancel()
+				Expect(err).Should(HaveOccurred())
+			}
+			Expect(nonces).Should(Equal([]uint64{nonces[0], nonces[0] + 1}))
+		})
+
+		It("should re-use the nonce once the transaction and its replacements are dropped", func() {
+			chain.onSend = func(chain *fakeChain, tx *types.Transaction) {
+				delete(chain.pool, tx.Hash())
+			}
+			account.SetReplacementPolicy(&beth.ReplacementPolicy{Interval: 100 * time.Millisecond})
+
+			nonces := []uint64{}
+			for i := 0; i < 2; i++ {
+				ctx, cancel := context.WithTimeout(context.Background(), 2500*time.Millisecond)
+				_, err := account.TransactWithResult(ctx, nil, send(&nonces), func() bool { return false }, 0)
+				cancel()
+				Expect(err).Should(HaveOccurred())
+			}
+			Expect(nonces).Should(Equal([]uint64{nonces[0], nonces[0]}))
+
+			chain.mu.Lock()
+			defer chain.mu.Unlock()
+			Expect(len(chain.sent)).Should(BeNumerically(">", 2))
+		})
+	})
+})
@@ -0,0 +1,111 @@
+package beth
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"time"
+)
+
+// RetryPolicy defines how a failed read or transaction is retried. The delay
+// before each retry starts at the initial delay and grows by the multiplier
+// after every failed attempt, until it saturates at the max delay.
+type RetryPolicy struct {
+
+	// InitialDelay before the first retry.
+	InitialDelay time.Duration
+
+	// Multiplier by which the delay increases after every retry. Multipliers
+	// below 1 are treated as 1, so the delay never decreases.
+	Multiplier float64
+
+	// MaxDelay at which the delay saturates. A zero max delay does not
+	// saturate the delay.
+	MaxDelay time.Duration
+
+	// Jitter is the fraction, between 0 and 1, by which every delay is
+	// randomly shortened, so that many clients do not retry in lockstep.
+	Jitter float64
+
+	// MaxAttempts is the total number of attempts, including the first, after
+	// which the last error is returned. A zero max attempts keeps retrying
+	// until the context is done.
+	MaxAttempts int
+
+	// Retryable returns true if an attempt that failed with the given error
+	// should be retried. A nil Retryable retries all errors that are not
+	// known to be permanent, as reported by IsRetryable.
+	Retryable func(err error) bool
+}
+
+// DefaultRetryPolicy keeps retrying until the context is done, starting at a
+// delay of 1 second and increasing it by 1.6 times up to 30 seconds.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		InitialDelay: time.Second,
+		Multiplier:   1.6,
+		MaxDelay:     30 * time.Second,
+	}
+}
+
+// retryPolicyKey is the context key of a RetryPolicy.
+type retryPolicyKey struct{}
+
+// WithRetryPolicy returns a copy of the context that carries the policy. Reads
+// and transactions made with the context use the policy instead of the policy
+// of their Client or Account.
+func WithRetryPolicy(ctx context.Context, policy RetryPolicy) context.Context {
+	return context.WithValue(ctx, retryPolicyKey{}, policy)
+}
+
+// retryPolicy returns the policy carried by the context, or the given policy
+// if the context does not carry one. If neither exists, the default policy is
+// returned.
+func retryPolicy(ctx context.Context, policy *RetryPolicy) RetryPolicy {
+	if ctxPolicy, ok := ctx.Value(retryPolicyKey{}).(RetryPolicy); ok {
+		return ctxPolicy
+	}
+	if policy != nil {
+		return *policy
+	}
+	return DefaultRetryPolicy()
+}
+
+// delay returns the delay after the given attempt failed, where the first
+// attempt is 1.
+func (policy RetryPolicy) delay(attempt int) time.Duration {
+	if policy.InitialDelay <= 0 {
+		return 0
+	}
+	multiplier := math.Max(policy.Multiplier, 1)
+	delay := float64(policy.InitialDelay) * math.Pow(multiplier, float64(attempt-1))
+	if policy.MaxDelay > 0 && delay > float64(policy.MaxDelay) {
+		delay = float64(policy.MaxDelay)
+	}
+	if policy.Jitter > 0 {
+		delay *= 1 - math.Min(policy.Jitter, 1)*rand.Float64()
+	}
+
+	// Without a max delay, the delay grows without bound, so it saturates at
+	// the longest duration instead of overflowing
+	if delay >= math.MaxInt64 {
+		return math.MaxInt64
+	}
+	return time.Duration(delay)
+}
+
+// retryable returns true if an attempt that failed with the error should be
+// retried. Without a Retryable function, errors are retried unless they are
+// known to be permanent.
+func (policy RetryPolicy) retryable(err error) bool {
+	if policy.Retryable == nil {
+		return IsRetryable(err)
+	}
+	return policy.Retryable(err)
+}
+
+// exhausted returns true if no more attempts are allowed after the given
+// attempt, where the first attempt is 1.
+func (policy RetryPolicy) exhausted(attempt int) bool {
+	return policy.MaxAttempts > 0 && attempt >= policy.MaxAttempts
+}
@@ -0,0 +1,146 @@
+package beth_test
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/republicprotocol/beth-go"
+)
+
+var errReadFailed = errors.New("read failed")
+
+var _ = Describe("retry policies", func() {
+
+	fastPolicy := func(maxAttempts int) *beth.RetryPolicy {
+		return &beth.RetryPolicy{
+			InitialDelay: time.Millisecond,
+			Multiplier:   2,
+			MaxDelay:     10 * time.Millisecond,
+			Jitter:       0.5,
+			MaxAttempts:  maxAttempts,
+		}
+	}
+
+	Context("when a client reads with a retry policy", func() {
+		It("should not overflow the delay when it has no max delay", func() {
+			client := beth.Client{}
+			client.SetRetryPolicy(&beth.RetryPolicy{
+				InitialDelay: time.Nanosecond,
+				Multiplier:   1e30,
+				MaxAttempts:  5,
+			})
+
+			// The second delay is far longer than the context, so the read is
+			// only attempted twice
+			ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+			defer cancel()
+			calls := 0
+			err := client.Get(ctx, func() error {
+				calls++
+				return errReadFailed
+			})
+			Expect(err).Should(Equal(errReadFailed))
+			Expect(calls).Should(Equal(2))
+			Expect(ctx.Err()).Should(HaveOccurred())
+		})
+
+		It("should not overflow the delay when it has no max delay and has jitter", func() {
+			client := beth.Client{}
+			client.SetRetryPolicy(&beth.RetryPolicy{
+				InitialDelay: time.Nanosecond,
+				Multiplier:   1e300,
+				Jitter:       0.5,
+				MaxAttempts:  5,
+			})
+
+			ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+			defer cancel()
+			calls := 0
+			err := client.Get(ctx, func() error {
+				calls++
+				return errReadFailed
+			})
+			Expect(err).Should(Equal(errReadFailed))
+			Expect(calls).Should(Equal(2))
+		})
+
+		It("should stop retrying after the max attempts", func() {
+			client := beth.Client{}
+			client.SetRetryPolicy(fastPolicy(3))
+
+			calls := 0
+			err := client.Get(context.Background(), func() error {
+				calls++
+				return errReadFailed
+			})
+			Expect(err).Should(Equal(errReadFailed))
+			Expect(calls).Should(Equal(3))
+		})
+
+		It("should not retry errors that are not retryable", func() {
+			policy := fastPolicy(0)
+			policy.Retryable = func(err error) bool {
+				return err != errReadFailed
+			}
+			client := beth.Client{}
+			client.SetRetryPolicy(policy)
+
+			calls := 0
+			err := client.Get(context.Background(), func() error {
+				calls++
+				return errReadFailed
+			})
+			Expect(err).Should(Equal(errReadFailed))
+			Expect(calls).Should(Equal(1))
+		})
+
+		It("should not retry errors that are known to be permanent without a retryable function", func() {
+			client := beth.Client{}
+			client.SetRetryPolicy(fastPolicy(0))
+
+			calls := 0
+			err := client.Get(context.Background(), func() error {
+				calls++
+				return beth.ErrInsufficientFunds
+			})
+			Expect(err).Should(Equal(beth.ErrInsufficientFunds))
+			Expect(calls).Should(Equal(1))
+		})
+
+		It("should keep retrying until the read succeeds", func() {
+			client := beth.Client{}
+			client.SetRetryPolicy(fastPolicy(0))
+
+			calls := 0
+			err := client.Get(context.Background(), func() error {
+				calls++
+				if calls < 5 {
+					return errReadFailed
+				}
+				return nil
+			})
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(calls).Should(Equal(5))
+		})
+	})
+
+	Context("when a call carries a retry policy", func() {
+		It("should override the retry policy of the client", func() {
+			client := beth.Client{}
+			client.SetRetryPolicy(fastPolicy(5))
+
+			calls := 0
+			ctx := beth.WithRetryPolicy(context.Background(), *fastPolicy(1))
+			err := client.Get(ctx, func() error {
+				calls++
+				return errReadFailed
+			})
+			Expect(err).Should(Equal(errReadFailed))
+			Expect(calls).Should(Equal(1))
+		})
+	})
+})
@@ -0,0 +1,146 @@
+package beth
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	ethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+var (
+	// errorSelector is the selector of `Error(string)`, which is used by
+	// `revert` and `require`.
+	errorSelector = []byte{0x08, 0xc3, 0x79, 0xa0}
+
+	// panicSelector is the selector of `Panic(uint256)`, which is used by
+	// failing assertions and arithmetic errors.
+	panicSelector = []byte{0x4e, 0x48, 0x7b, 0x71}
+)
+
+// panicReasons maps the codes of `Panic(uint256)` to their meaning.
+var panicReasons = map[uint64]string{
+	0x00: "generic panic",
+	0x01: "assertion failed",
+	0x11: "arithmetic overflow or underflow",
+	0x12: "division or modulo by zero",
+	0x21: "invalid enum value",
+	0x22: "invalid storage byte array",
+	0x31: "pop from empty array",
+	0x32: "array index out of bounds",
+	0x41: "out of memory",
+	0x51: "call to zero-initialized function",
+}
+
+// ErrReverted indicates that a transaction was mined, but reverted. The
+// Reason is decoded from the revert data when it is an `Error(string)` or a
+// `Panic(uint256)`, and is empty otherwise. If the transaction could not be
+// replayed to recover its revert data, the Reason is empty and ReplayErr is
+// the error of the replay.
+type ErrReverted struct {
+	Reason    string
+	Data      []byte
+	ReplayErr error
+}
+
+// Error implements the error interface.
+func (err *ErrReverted) Error() string {
+	if err.Reason == "" {
+		return "transaction reverted"
+	}
+	return fmt.Sprintf("transaction reverted: %s", err.Reason)
+}
+
+// Unwrap returns the error of the replay, if the transaction could not be
+// replayed.
+func (err *ErrReverted) Unwrap() error {
+	return err.ReplayErr
+}
+
+// UnpackRevert returns an ErrReverted for the given revert data, decoding the
+// reason if the data is an `Error(string)` or a `Panic(uint256)`.
+func UnpackRevert(data []byte) *ErrReverted {
+	err := &ErrReverted{
+		Data: data,
+	}
+	if len(data) < 4 {
+		return err
+	}
+
+	switch {
+	case bytes.Equal(data[:4], errorSelector):
+		if reason, unpackErr := abi.UnpackRevert(data); unpackErr == nil {
+			err.Reason = reason
+		}
+
+	case bytes.Equal(data[:4], panicSelector):
+		if len(data) != 36 {
+			return err
+		}
+		code := new(big.Int).SetBytes(data[4:])
+		reason, ok := panicReasons[code.Uint64()]
+		if !ok || !code.IsUint64() {
+			reason = "unknown panic"
+		}
+		err.Reason = fmt.Sprintf("%s (0x%x)", reason, code)
+	}
+	return err
+}
+
+// The RevertPolicy determines whether Transact retries a transaction that was
+// mined, but reverted.
+type RevertPolicy uint8
+
+// RevertPolicy values.
+const (
+	FailOnRevert = RevertPolicy(iota)
+	RetryOnRevert
+)
+
+// revertError replays a reverted transaction with `eth_call` on top of the
+// parent of the block that it was mined in, and returns an ErrReverted with the
+// revert data returned by the replay. Errors of the node that are not about the
+// replayed call, and errors that do not come from the node, are returned in
+// the ReplayErr of the ErrReverted.
+func (account *account) revertError(ctx context.Context, tx *types.Transaction, receipt *types.Receipt) error {
+	msg := ethereum.CallMsg{
+		From:  account.Address(),
+		To:    tx.To(),
+		Gas:   tx.Gas(),
+		Value: tx.Value(),
+		Data:  tx.Data(),
+	}
+	parent := new(big.Int).Sub(receipt.BlockNumber, big.NewInt(1))
+	_, err := account.client.Backend().CallContract(ctx, msg, parent)
+	if err == nil {
+		// The replay did not revert, so the reason cannot be recovered
+		return &ErrReverted{}
+	}
+
+	var dataErr rpc.DataError
+	if errors.As(err, &dataErr) {
+		if hexData, ok := dataErr.ErrorData().(string); ok {
+			if data, decodeErr := hexutil.Decode(hexData); decodeErr == nil {
+				return UnpackRevert(data)
+			}
+		}
+	}
+
+	// Nodes that return no revert data still report the revert in the message
+	var rpcErr rpc.Error
+	if errors.As(err, &rpcErr) && (rpcErr.ErrorCode() == 3 || strings.Contains(strings.ToLower(err.Error()), "revert")) {
+		return &ErrReverted{
+			Reason: err.Error(),
+		}
+	}
+	return &ErrReverted{
+		ReplayErr: err,
+	}
+}
@@ -0,0 +1,50 @@
+package beth_test
+
+import (
+	"math/big"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/republicprotocol/beth-go"
+)
+
+var _ = Describe("revert reasons", func() {
+
+	Context("when the revert data is an Error(string)", func() {
+		It("should decode the reason", func() {
+			stringType, err := abi.NewType("string", "", nil)
+			Expect(err).ShouldNot(HaveOccurred())
+			packed, err := (abi.Arguments{{Type: stringType}}).Pack("insufficient balance")
+			Expect(err).ShouldNot(HaveOccurred())
+			data := append(common.FromHex("0x08c379a0"), packed...)
+
+			reverted := beth.UnpackRevert(data)
+			Expect(reverted.Reason).Should(Equal("insufficient balance"))
+			Expect(reverted.Data).Should(Equal(data))
+			Expect(reverted.Error()).Should(Equal("transaction reverted: insufficient balance"))
+		})
+	})
+
+	Context("when the revert data is a Panic(uint256)", func() {
+		It("should decode the panic code", func() {
+			data := append(common.FromHex("0x4e487b71"), common.LeftPadBytes(big.NewInt(0x11).Bytes(), 32)...)
+
+			reverted := beth.UnpackRevert(data)
+			Expect(reverted.Reason).Should(Equal("arithmetic overflow or underflow (0x11)"))
+		})
+	})
+
+	Context("when the revert data is a custom error", func() {
+		It("should keep the data without a reason", func() {
+			data := common.FromHex("0xdeadbeef")
+
+			reverted := beth.UnpackRevert(data)
+			Expect(reverted.Reason).Should(BeEmpty())
+			Expect(reverted.Data).Should(Equal(data))
+			Expect(reverted.Error()).Should(Equal("transaction reverted"))
+		})
+	})
+})
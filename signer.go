@@ -0,0 +1,248 @@
+package beth
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// ErrWrongSigner is returned when a remote signer signs a transaction with a
+// key that is not the key of the Signer.
+var ErrWrongSigner = errors.New("signed by the wrong key")
+
+// ErrSignedTxMismatch is returned when a remote signer returns a signed
+// transaction that differs from the transaction that it was asked to sign.
+var ErrSignedTxMismatch = errors.New("signed transaction does not match the transaction to be signed")
+
+// ErrSignHashNotSupported is returned by signers that cannot sign a raw hash,
+// such as remote signers, which only sign data with the Ethereum signed
+// message header.
+var ErrSignHashNotSupported = errors.New("signer cannot sign raw hashes")
+
+// Signer signs the transactions and messages of an Account. It can hold the
+// private key in memory, or forward signatures to a hardware wallet, a remote
+// signer or a key management service.
+type Signer interface {
+
+	// Address of the key that signs.
+	Address() common.Address
+
+	// SignTx signs the transaction for the chain with the given ID.
+	SignTx(ctx context.Context, tx *types.Transaction, chainID *big.Int) (*types.Transaction, error)
+
+	// SignHash signs a 32 byte hash, and returns the signature in the
+	// [R || S || V] format, where V is 0 or 1. Signers that cannot sign a raw
+	// hash return ErrSignHashNotSupported.
+	SignHash(ctx context.Context, hash []byte) ([]byte, error)
+
+	// SignText signs the hash of the data prefixed with the Ethereum signed
+	// message header, as eth_sign does, and returns the signature in the
+	// [R || S || V] format, where V is 0 or 1.
+	SignText(ctx context.Context, data []byte) ([]byte, error)
+}
+
+type privateKeySigner struct {
+	address    common.Address
+	privateKey *ecdsa.PrivateKey
+}
+
+// NewPrivateKeySigner returns a Signer that holds the private key in memory.
+func NewPrivateKeySigner(privateKey *ecdsa.PrivateKey) Signer {
+	return &privateKeySigner{
+		address:    crypto.PubkeyToAddress(privateKey.PublicKey),
+		privateKey: privateKey,
+	}
+}
+
+func (signer *privateKeySigner) Address() common.Address {
+	return signer.address
+}
+
+func (signer *privateKeySigner) SignTx(ctx context.Context, tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+	return types.SignTx(tx, types.LatestSignerForChainID(chainID), signer.privateKey)
+}
+
+func (signer *privateKeySigner) SignHash(ctx context.Context, hash []byte) ([]byte, error) {
+	return crypto.Sign(hash, signer.privateKey)
+}
+
+func (signer *privateKeySigner) SignText(ctx context.Context, data []byte) ([]byte, error) {
+	return crypto.Sign(accounts.TextHash(data), signer.privateKey)
+}
+
+// NewKeystoreSigner returns a Signer for the key in the encrypted keystore
+// file at the path. The key is decrypted once, using the passphrase, and held
+// in memory.
+func NewKeystoreSigner(path, passphrase string) (Signer, error) {
+	keyJSON, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	key, err := keystore.DecryptKey(keyJSON, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	return NewPrivateKeySigner(key.PrivateKey), nil
+}
+
+// RemoteSignerOptions configure the JSON-RPC methods that a remote signer
+// calls.
+type RemoteSignerOptions struct {
+
+	// SignTxMethod is called with the arguments of a transaction. It returns
+	// the signed transaction as raw bytes, or as an object with a raw field.
+	SignTxMethod string
+
+	// SignTextMethod is called with the address and the data, which is sent
+	// as text/plain data to account_signData. It signs the data with the
+	// Ethereum signed message header, and returns the signature in the
+	// [R || S || V] format, where V is 0, 1, 27 or 28.
+	SignTextMethod string
+}
+
+// DefaultRemoteSignerOptions call the eth_signTransaction and eth_sign methods,
+// which are served by Web3Signer and by geth.
+func DefaultRemoteSignerOptions() RemoteSignerOptions {
+	return RemoteSignerOptions{
+		SignTxMethod:   "eth_signTransaction",
+		SignTextMethod: "eth_sign",
+	}
+}
+
+// ClefSignerOptions call the account_signTransaction and account_signData
+// methods served by Clef.
+func ClefSignerOptions() RemoteSignerOptions {
+	return RemoteSignerOptions{
+		SignTxMethod:   "account_signTransaction",
+		SignTextMethod: "account_signData",
+	}
+}
+
+type remoteSigner struct {
+	address   common.Address
+	rpcClient *rpc.Client
+	options   RemoteSignerOptions
+}
+
+// NewRemoteSigner returns a Signer that forwards signatures for the address to
+// the JSON-RPC signer at the URL. The URL can be an HTTP or WebSocket URL, or
+// the path of an IPC socket. Remote signers always prefix data with the
+// Ethereum signed message header before signing it, so the Signer returns
+// ErrSignHashNotSupported from SignHash, and only signs data using SignText.
+// Every signed transaction is checked to be signed by the address.
+func NewRemoteSigner(url string, address common.Address, options RemoteSignerOptions) (Signer, error) {
+	rpcClient, err := rpc.Dial(url)
+	if err != nil {
+		return nil, err
+	}
+	return &remoteSigner{
+		address:   address,
+		rpcClient: rpcClient,
+		options:   options,
+	}, nil
+}
+
+func (signer *remoteSigner) Address() common.Address {
+	return signer.address
+}
+
+func (signer *remoteSigner) SignTx(ctx context.Context, tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+	var result json.RawMessage
+	if err := signer.rpcClient.CallContext(ctx, &result, signer.options.SignTxMethod, signTxArgs(signer.address, tx, chainID)); err != nil {
+		return nil, rpcErr(err)
+	}
+
+	// The signed transaction is either returned as raw bytes, or as an
+	// object with a raw field
+	var raw hexutil.Bytes
+	if err := json.Unmarshal(result, &raw); err != nil {
+		var signed struct {
+			Raw hexutil.Bytes `json:"raw"`
+		}
+		if err := json.Unmarshal(result, &signed); err != nil {
+			return nil, fmt.Errorf("cannot decode signed transaction: %v", err)
+		}
+		raw = signed.Raw
+	}
+	signedTx := new(types.Transaction)
+	if err := signedTx.UnmarshalBinary(raw); err != nil {
+		return nil, fmt.Errorf("cannot decode signed transaction: %v", err)
+	}
+
+	// The signer must sign the requested transaction, and not one whose
+	// recipient, value or fees have been changed
+	txSigner := types.LatestSignerForChainID(chainID)
+	if txSigner.Hash(signedTx) != txSigner.Hash(tx) {
+		return nil, ErrSignedTxMismatch
+	}
+	sender, err := types.Sender(txSigner, signedTx)
+	if err != nil {
+		return nil, err
+	}
+	if sender != signer.address {
+		return nil, ErrWrongSigner
+	}
+	return signedTx, nil
+}
+
+func (signer *remoteSigner) SignHash(ctx context.Context, hash []byte) ([]byte, error) {
+	return nil, ErrSignHashNotSupported
+}
+
+func (signer *remoteSigner) SignText(ctx context.Context, data []byte) ([]byte, error) {
+	var signature hexutil.Bytes
+	var err error
+	if signer.options.SignTextMethod == ClefSignerOptions().SignTextMethod {
+		err = signer.rpcClient.CallContext(ctx, &signature, signer.options.SignTextMethod, "text/plain", signer.address, hexutil.Bytes(data))
+	} else {
+		err = signer.rpcClient.CallContext(ctx, &signature, signer.options.SignTextMethod, signer.address, hexutil.Bytes(data))
+	}
+	if err != nil {
+		return nil, rpcErr(err)
+	}
+	if len(signature) != crypto.SignatureLength {
+		return nil, fmt.Errorf("unexpected signature length %v", len(signature))
+	}
+	if signature[crypto.RecoveryIDOffset] >= 27 {
+		signature[crypto.RecoveryIDOffset] -= 27
+	}
+	return signature, nil
+}
+
+// signTxArgs returns the JSON-RPC arguments of a transaction that is signed by
+// a remote signer.
+func signTxArgs(from common.Address, tx *types.Transaction, chainID *big.Int) map[string]interface{} {
+	args := map[string]interface{}{
+		"from":    from,
+		"gas":     hexutil.Uint64(tx.Gas()),
+		"value":   (*hexutil.Big)(tx.Value()),
+		"nonce":   hexutil.Uint64(tx.Nonce()),
+		"data":    hexutil.Bytes(tx.Data()),
+		"chainId": (*hexutil.Big)(chainID),
+	}
+	if tx.To() != nil {
+		args["to"] = tx.To()
+	}
+	if tx.Type() == types.LegacyTxType {
+		args["gasPrice"] = (*hexutil.Big)(tx.GasPrice())
+	} else {
+		args["maxFeePerGas"] = (*hexutil.Big)(tx.GasFeeCap())
+		args["maxPriorityFeePerGas"] = (*hexutil.Big)(tx.GasTipCap())
+	}
+	if len(tx.AccessList()) > 0 {
+		args["accessList"] = tx.AccessList()
+	}
+	return args
+}
@@ -0,0 +1,269 @@
+package beth_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"io/ioutil"
+	"math/big"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/republicprotocol/beth-go"
+)
+
+// fakeSignTxArgs are the arguments of a transaction sent to a remote signer.
+type fakeSignTxArgs struct {
+	To                   *common.Address `json:"to"`
+	Gas                  hexutil.Uint64  `json:"gas"`
+	GasPrice             *hexutil.Big    `json:"gasPrice"`
+	MaxFeePerGas         *hexutil.Big    `json:"maxFeePerGas"`
+	MaxPriorityFeePerGas *hexutil.Big    `json:"maxPriorityFeePerGas"`
+	Value                *hexutil.Big    `json:"value"`
+	Nonce                hexutil.Uint64  `json:"nonce"`
+	Data                 hexutil.Bytes   `json:"data"`
+	ChainID              *hexutil.Big    `json:"chainId"`
+}
+
+// fakeSigner is a stand-in for a remote signer that signs with its key. It
+// serves the `eth` namespace of Web3Signer, and the `account` namespace of
+// Clef.
+type fakeSigner struct {
+	key *ecdsa.PrivateKey
+
+	// tamper, if not nil, changes the transaction before it is signed
+	tamper func(args *fakeSignTxArgs)
+}
+
+func (signer *fakeSigner) sign(args fakeSignTxArgs) (*types.Transaction, error) {
+	if signer.tamper != nil {
+		signer.tamper(&args)
+	}
+	var txData types.TxData
+	if args.GasPrice != nil {
+		txData = &types.LegacyTx{Nonce: uint64(args.Nonce), GasPrice: args.GasPrice.ToInt(), Gas: uint64(args.Gas), To: args.To, Value: args.Value.ToInt(), Data: args.Data}
+	} else {
+		txData = &types.DynamicFeeTx{ChainID: args.ChainID.ToInt(), Nonce: uint64(args.Nonce), GasTipCap: args.MaxPriorityFeePerGas.ToInt(), GasFeeCap: args.MaxFeePerGas.ToInt(), Gas: uint64(args.Gas), To: args.To, Value: args.Value.ToInt(), Data: args.Data}
+	}
+	return types.SignTx(types.NewTx(txData), types.LatestSignerForChainID(args.ChainID.ToInt()), signer.key)
+}
+
+// signText signs the data with the Ethereum signed message header, and
+// returns the signature with a V of 27 or 28.
+func (signer *fakeSigner) signText(data hexutil.Bytes) (hexutil.Bytes, error) {
+	signature, err := crypto.Sign(accounts.TextHash(data), signer.key)
+	if err != nil {
+		return nil, err
+	}
+	signature[crypto.RecoveryIDOffset] += 27
+	return signature, nil
+}
+
+// fakeSignerEth is the `eth` namespace of a fakeSigner.
+type fakeSignerEth struct {
+	*fakeSigner
+}
+
+func (signer fakeSignerEth) SignTransaction(args fakeSignTxArgs) (hexutil.Bytes, error) {
+	tx, err := signer.sign(args)
+	if err != nil {
+		return nil, err
+	}
+	return tx.MarshalBinary()
+}
+
+func (signer fakeSignerEth) Sign(addr common.Address, data hexutil.Bytes) (hexutil.Bytes, error) {
+	return signer.signText(data)
+}
+
+// fakeSignerAccount is the `account` namespace of a fakeSigner.
+type fakeSignerAccount struct {
+	*fakeSigner
+}
+
+func (signer fakeSignerAccount) SignTransaction(args fakeSignTxArgs) (map[string]interface{}, error) {
+	tx, err := signer.sign(args)
+	if err != nil {
+		return nil, err
+	}
+	raw, err := tx.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{"raw": hexutil.Bytes(raw), "tx": tx}, nil
+}
+
+func (signer fakeSignerAccount) SignData(contentType string, addr common.Address, data hexutil.Bytes) (hexutil.Bytes, error) {
+	return signer.signText(data)
+}
+
+var _ = Describe("signers", func() {
+
+	var key *ecdsa.PrivateKey
+
+	BeforeEach(func() {
+		var err error
+		key, err = crypto.GenerateKey()
+		Expect(err).ShouldNot(HaveOccurred())
+	})
+
+	// expectSigned expects the signer to sign transactions, text and, if the
+	// signer can sign raw hashes, hashes with the key.
+	expectSigned := func(signer beth.Signer, signsHashes bool) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+
+		address := crypto.PubkeyToAddress(key.PublicKey)
+		Expect(signer.Address()).Should(Equal(address))
+
+		chainID := big.NewInt(1337)
+		tx := types.NewTx(&types.DynamicFeeTx{ChainID: chainID, Nonce: 1, GasTipCap: big.NewInt(1), GasFeeCap: big.NewInt(2), Gas: 21000, To: &common.Address{}, Value: big.NewInt(3)})
+		signedTx, err := signer.SignTx(ctx, tx, chainID)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(types.Sender(types.LatestSignerForChainID(chainID), signedTx)).Should(Equal(address))
+		Expect(signedTx.Value()).Should(Equal(big.NewInt(3)))
+
+		text := []byte("message")
+		signature, err := signer.SignText(ctx, text)
+		Expect(err).ShouldNot(HaveOccurred())
+		publicKey, err := crypto.SigToPub(accounts.TextHash(text), signature)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(crypto.PubkeyToAddress(*publicKey)).Should(Equal(address))
+
+		msgHash := crypto.Keccak256(text)
+		signature, err = signer.SignHash(ctx, msgHash)
+		if !signsHashes {
+			Expect(err).Should(Equal(beth.ErrSignHashNotSupported))
+			return
+		}
+		Expect(err).ShouldNot(HaveOccurred())
+		publicKey, err = crypto.SigToPub(msgHash, signature)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(crypto.PubkeyToAddress(*publicKey)).Should(Equal(address))
+	}
+
+	Context("when the private key is in memory", func() {
+		It("should sign with the private key", func() {
+			expectSigned(beth.NewPrivateKeySigner(key), true)
+		})
+	})
+
+	Context("when the private key is in a keystore file", func() {
+		var path string
+
+		BeforeEach(func() {
+			dir, err := ioutil.TempDir("", "beth")
+			Expect(err).ShouldNot(HaveOccurred())
+			path = filepath.Join(dir, "keystore.json")
+			keyJSON, err := keystore.EncryptKey(&keystore.Key{Address: crypto.PubkeyToAddress(key.PublicKey), PrivateKey: key}, "passphrase", keystore.LightScryptN, keystore.LightScryptP)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(ioutil.WriteFile(path, keyJSON, 0600)).Should(Succeed())
+		})
+
+		AfterEach(func() {
+			os.RemoveAll(filepath.Dir(path))
+		})
+
+		It("should sign with the decrypted private key", func() {
+			signer, err := beth.NewKeystoreSigner(path, "passphrase")
+			Expect(err).ShouldNot(HaveOccurred())
+			expectSigned(signer, true)
+		})
+
+		It("should return an error if the passphrase is wrong", func() {
+			_, err := beth.NewKeystoreSigner(path, "wrong")
+			Expect(err).Should(Equal(keystore.ErrDecrypt))
+		})
+	})
+
+	Context("when the private key is in a remote signer", func() {
+		var signer *fakeSigner
+		var server *httptest.Server
+
+		BeforeEach(func() {
+			signer = &fakeSigner{key: key}
+			rpcServer := rpc.NewServer()
+			Expect(rpcServer.RegisterName("eth", fakeSignerEth{signer})).Should(Succeed())
+			Expect(rpcServer.RegisterName("account", fakeSignerAccount{signer})).Should(Succeed())
+			server = httptest.NewServer(rpcServer)
+		})
+
+		AfterEach(func() {
+			server.Close()
+		})
+
+		It("should sign with eth_signTransaction and eth_sign", func() {
+			remoteSigner, err := beth.NewRemoteSigner(server.URL, crypto.PubkeyToAddress(key.PublicKey), beth.DefaultRemoteSignerOptions())
+			Expect(err).ShouldNot(HaveOccurred())
+			expectSigned(remoteSigner, false)
+		})
+
+		It("should sign with the methods of Clef", func() {
+			remoteSigner, err := beth.NewRemoteSigner(server.URL, crypto.PubkeyToAddress(key.PublicKey), beth.ClefSignerOptions())
+			Expect(err).ShouldNot(HaveOccurred())
+			expectSigned(remoteSigner, false)
+		})
+
+		It("should return ErrWrongSigner if a transaction is signed by another key", func() {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+			defer cancel()
+
+			remoteSigner, err := beth.NewRemoteSigner(server.URL, common.Address{}, beth.DefaultRemoteSignerOptions())
+			Expect(err).ShouldNot(HaveOccurred())
+			tx := types.NewTx(&types.LegacyTx{Nonce: 1, GasPrice: big.NewInt(1), Gas: 21000, To: &common.Address{}, Value: big.NewInt(3)})
+			_, err = remoteSigner.SignTx(ctx, tx, big.NewInt(1337))
+			Expect(err).Should(Equal(beth.ErrWrongSigner))
+		})
+
+		It("should return ErrSignedTxMismatch if the signed transaction has been changed", func() {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+			defer cancel()
+
+			signer.tamper = func(args *fakeSignTxArgs) {
+				to := common.HexToAddress("0x000000000000000000000000000000000000dEaD")
+				args.To = &to
+			}
+			remoteSigner, err := beth.NewRemoteSigner(server.URL, crypto.PubkeyToAddress(key.PublicKey), beth.DefaultRemoteSignerOptions())
+			Expect(err).ShouldNot(HaveOccurred())
+			tx := types.NewTx(&types.LegacyTx{Nonce: 1, GasPrice: big.NewInt(1), Gas: 21000, To: &common.Address{}, Value: big.NewInt(3)})
+			_, err = remoteSigner.SignTx(ctx, tx, big.NewInt(1337))
+			Expect(err).Should(Equal(beth.ErrSignedTxMismatch))
+		})
+
+		It("should send the transactions of an account", func() {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+			defer cancel()
+
+			chain := newSimulatedChain(2)
+			defer chain.close()
+			signer.key = chain.keys[0]
+
+			client, err := beth.Connect(chain.url())
+			Expect(err).ShouldNot(HaveOccurred())
+			remoteSigner, err := beth.NewRemoteSigner(server.URL, crypto.PubkeyToAddress(chain.keys[0].PublicKey), beth.DefaultRemoteSignerOptions())
+			Expect(err).ShouldNot(HaveOccurred())
+			account, err := beth.NewAccountWithSigner(client, remoteSigner, beth.NewFixedGasPriceOracle(big.NewInt(10e9)))
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(account.Address()).Should(Equal(remoteSigner.Address()))
+
+			to := crypto.PubkeyToAddress(chain.keys[1].PublicKey)
+			value := big.NewInt(1e18)
+			_, err = account.Transfer(ctx, to, value, nil, 0, false)
+			Expect(err).ShouldNot(HaveOccurred())
+			balance, err := client.BalanceOf(ctx, to)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(balance).Should(Equal(new(big.Int).Add(simulatedChainFunds, value)))
+		})
+	})
+})
@@ -0,0 +1,474 @@
+package beth_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	. "github.com/onsi/gomega"
+
+	ethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind/backends"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/consensus/misc"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/asm"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/republicprotocol/beth-go"
+	"github.com/republicprotocol/beth-go/test"
+)
+
+// simulatedChain is a chain backed by the simulated backend of go-ethereum,
+// and served over JSON-RPC, so that accounts and clients can be tested
+// without a network. A block is mined for every transaction that is sent, and
+// an empty block is mined every simulatedBlockTime. The chain has a deployed
+// Bethtest contract, and a CompatibleERC20 token whose supply is held by the
+// first key.
+type simulatedChain struct {
+	backend *backends.SimulatedBackend
+	rpc     *rpc.Server
+	server  *httptest.Server
+	done    chan struct{}
+	mining  sync.WaitGroup
+
+	// keys of the accounts that are funded in the genesis block
+	keys []*ecdsa.PrivateKey
+
+	bethtest common.Address
+	erc20    common.Address
+}
+
+// simulatedChainFunds is the balance of every funded account, and the supply
+// of the token.
+var simulatedChainFunds = new(big.Int).Mul(big.NewInt(1000), big.NewInt(1e18))
+
+// simulatedBlockTime is the time between the empty blocks of a simulated
+// chain.
+const simulatedBlockTime = 20 * time.Millisecond
+
+// newSimulatedChain returns a simulated chain with the given number of funded
+// accounts.
+func newSimulatedChain(accounts int) *simulatedChain {
+	chain := &simulatedChain{
+		done: make(chan struct{}),
+		keys: make([]*ecdsa.PrivateKey, accounts),
+	}
+	alloc := core.GenesisAlloc{}
+	for i := range chain.keys {
+		key, err := crypto.GenerateKey()
+		Expect(err).ShouldNot(HaveOccurred())
+		chain.keys[i] = key
+		alloc[crypto.PubkeyToAddress(key.PublicKey)] = core.GenesisAccount{Balance: simulatedChainFunds}
+	}
+	chain.backend = backends.NewSimulatedBackend(alloc, 10000000)
+
+	// Deploy the contracts
+	deployer, err := bind.NewKeyedTransactorWithChainID(chain.keys[0], chain.backend.Blockchain().Config().ChainID)
+	Expect(err).ShouldNot(HaveOccurred())
+	chain.bethtest, _, _, err = test.DeployBethtest(deployer, chain.backend)
+	Expect(err).ShouldNot(HaveOccurred())
+	erc20ABI, err := beth.CompatibleERC20MetaData.GetAbi()
+	Expect(err).ShouldNot(HaveOccurred())
+	chain.erc20, _, _, err = bind.DeployContract(deployer, *erc20ABI, erc20Bytecode(simulatedChainFunds), chain.backend)
+	Expect(err).ShouldNot(HaveOccurred())
+	chain.backend.Commit()
+
+	// Serve the chain over JSON-RPC
+	chain.rpc = rpc.NewServer()
+	Expect(chain.rpc.RegisterName("eth", &simulatedEth{chain: chain})).Should(Succeed())
+	Expect(chain.rpc.RegisterName("net", &simulatedNet{chain: chain})).Should(Succeed())
+	chain.server = httptest.NewServer(chain.rpc.WebsocketHandler([]string{"*"}))
+
+	chain.mining.Add(1)
+	go func() {
+		defer chain.mining.Done()
+		ticker := time.NewTicker(simulatedBlockTime)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-chain.done:
+				return
+			case <-ticker.C:
+				chain.backend.Commit()
+			}
+		}
+	}()
+	return chain
+}
+
+// url of the WebSocket JSON-RPC endpoint of the chain, over which new heads
+// can be subscribed to.
+func (chain *simulatedChain) url() string {
+	return "ws://" + strings.TrimPrefix(chain.server.URL, "http://")
+}
+
+// newAccount returns an account for the funded key at the given index.
+func (chain *simulatedChain) newAccount(i int) beth.Account {
+	account, err := beth.NewAccount(chain.url(), chain.keys[i], beth.NewFixedGasPriceOracle(big.NewInt(10e9)))
+	Expect(err).ShouldNot(HaveOccurred())
+	return account
+}
+
+// mine the given number of empty blocks.
+func (chain *simulatedChain) mine(blocks int) {
+	for i := 0; i < blocks; i++ {
+		chain.backend.Commit()
+	}
+}
+
+func (chain *simulatedChain) close() {
+	close(chain.done)
+	chain.mining.Wait()
+	chain.server.Close()
+	chain.rpc.Stop()
+	chain.backend.Close()
+}
+
+// simulatedNet is the `net` namespace of a simulated chain.
+type simulatedNet struct {
+	chain *simulatedChain
+}
+
+func (api *simulatedNet) Version() string {
+	return api.chain.backend.Blockchain().Config().ChainID.String()
+}
+
+// simulatedEth is the `eth` namespace of a simulated chain.
+type simulatedEth struct {
+	chain *simulatedChain
+}
+
+// callArgs are the arguments of `eth_call` and `eth_estimateGas`.
+type callArgs struct {
+	From     common.Address  `json:"from"`
+	To       *common.Address `json:"to"`
+	Gas      *hexutil.Uint64 `json:"gas"`
+	GasPrice *hexutil.Big    `json:"gasPrice"`
+	Value    *hexutil.Big    `json:"value"`
+	Data     hexutil.Bytes   `json:"data"`
+	Input    hexutil.Bytes   `json:"input"`
+}
+
+func (args callArgs) msg() ethereum.CallMsg {
+	msg := ethereum.CallMsg{From: args.From, To: args.To, Data: args.Data}
+	if args.Input != nil {
+		msg.Data = args.Input
+	}
+	if args.Gas != nil {
+		msg.Gas = uint64(*args.Gas)
+	}
+	if args.GasPrice != nil {
+		msg.GasPrice = args.GasPrice.ToInt()
+	}
+	if args.Value != nil {
+		msg.Value = args.Value.ToInt()
+	}
+	return msg
+}
+
+// blockNumber returns the number of a block, or nil for the latest block. The
+// safe and finalized blocks are the latest block, because blocks of the
+// simulated backend cannot be reorged.
+func (api *simulatedEth) blockNumber(ctx context.Context, block rpc.BlockNumberOrHash) (*big.Int, error) {
+	if hash, ok := block.Hash(); ok {
+		header, err := api.chain.backend.HeaderByHash(ctx, hash)
+		if err != nil {
+			return nil, err
+		}
+		return header.Number, nil
+	}
+	number, _ := block.Number()
+	if number < 0 {
+		return nil, nil
+	}
+	return big.NewInt(number.Int64()), nil
+}
+
+func (api *simulatedEth) ChainId() *hexutil.Big {
+	return (*hexutil.Big)(api.chain.backend.Blockchain().Config().ChainID)
+}
+
+func (api *simulatedEth) BlockNumber() hexutil.Uint64 {
+	return hexutil.Uint64(api.chain.backend.Blockchain().CurrentBlock().NumberU64())
+}
+
+func (api *simulatedEth) GetBlockByNumber(ctx context.Context, number rpc.BlockNumber, full bool) (*types.Header, error) {
+	var blockNumber *big.Int
+	if number >= 0 {
+		blockNumber = big.NewInt(number.Int64())
+	}
+	header, err := api.chain.backend.HeaderByNumber(ctx, blockNumber)
+	if err == ethereum.NotFound || header == nil {
+		return nil, nil
+	}
+	return header, err
+}
+
+func (api *simulatedEth) GetBlockByHash(ctx context.Context, hash common.Hash, full bool) (*types.Header, error) {
+	header, err := api.chain.backend.HeaderByHash(ctx, hash)
+	if err == ethereum.NotFound || header == nil {
+		return nil, nil
+	}
+	return header, err
+}
+
+func (api *simulatedEth) GetBalance(ctx context.Context, addr common.Address, block rpc.BlockNumberOrHash) (*hexutil.Big, error) {
+	blockNumber, err := api.blockNumber(ctx, block)
+	if err != nil {
+		return nil, err
+	}
+	balance, err := api.chain.backend.BalanceAt(ctx, addr, blockNumber)
+	return (*hexutil.Big)(balance), err
+}
+
+func (api *simulatedEth) GetTransactionCount(ctx context.Context, addr common.Address, block rpc.BlockNumberOrHash) (hexutil.Uint64, error) {
+	if number, ok := block.Number(); ok && number == rpc.PendingBlockNumber {
+		nonce, err := api.chain.backend.PendingNonceAt(ctx, addr)
+		return hexutil.Uint64(nonce), err
+	}
+	blockNumber, err := api.blockNumber(ctx, block)
+	if err != nil {
+		return 0, err
+	}
+	nonce, err := api.chain.backend.NonceAt(ctx, addr, blockNumber)
+	return hexutil.Uint64(nonce), err
+}
+
+func (api *simulatedEth) GetCode(ctx context.Context, addr common.Address, block rpc.BlockNumberOrHash) (hexutil.Bytes, error) {
+	if number, ok := block.Number(); ok && number == rpc.PendingBlockNumber {
+		return api.chain.backend.PendingCodeAt(ctx, addr)
+	}
+	blockNumber, err := api.blockNumber(ctx, block)
+	if err != nil {
+		return nil, err
+	}
+	return api.chain.backend.CodeAt(ctx, addr, blockNumber)
+}
+
+func (api *simulatedEth) Call(ctx context.Context, args callArgs, block rpc.BlockNumberOrHash) (hexutil.Bytes, error) {
+	if number, ok := block.Number(); ok && number == rpc.PendingBlockNumber {
+		return api.chain.backend.PendingCallContract(ctx, args.msg())
+	}
+	blockNumber, err := api.blockNumber(ctx, block)
+	if err != nil {
+		return nil, err
+	}
+	if blockNumber != nil && blockNumber.Uint64() == api.chain.backend.Blockchain().CurrentBlock().NumberU64() {
+		blockNumber = nil
+	}
+	return api.chain.backend.CallContract(ctx, args.msg(), blockNumber)
+}
+
+func (api *simulatedEth) EstimateGas(ctx context.Context, args callArgs) (hexutil.Uint64, error) {
+	gas, err := api.chain.backend.EstimateGas(ctx, args.msg())
+	return hexutil.Uint64(gas), err
+}
+
+func (api *simulatedEth) GasPrice(ctx context.Context) (*hexutil.Big, error) {
+	gasPrice, err := api.chain.backend.SuggestGasPrice(ctx)
+	return (*hexutil.Big)(gasPrice), err
+}
+
+func (api *simulatedEth) MaxPriorityFeePerGas(ctx context.Context) (*hexutil.Big, error) {
+	gasTipCap, err := api.chain.backend.SuggestGasTipCap(ctx)
+	return (*hexutil.Big)(gasTipCap), err
+}
+
+// feeHistory is the result of `eth_feeHistory`.
+type feeHistory struct {
+	OldestBlock  *hexutil.Big     `json:"oldestBlock"`
+	Reward       [][]*hexutil.Big `json:"reward,omitempty"`
+	BaseFee      []*hexutil.Big   `json:"baseFeePerGas,omitempty"`
+	GasUsedRatio []float64        `json:"gasUsedRatio"`
+}
+
+// FeeHistory returns the base fees and the tips paid at the given percentiles
+// in the blocks up to the last block, and the base fee of the next block.
+func (api *simulatedEth) FeeHistory(ctx context.Context, blockCount hexutil.Uint, lastBlock rpc.BlockNumber, rewardPercentiles []float64) (*feeHistory, error) {
+	blockchain := api.chain.backend.Blockchain()
+	last := blockchain.CurrentBlock().NumberU64()
+	if lastBlock >= 0 && uint64(lastBlock) < last {
+		last = uint64(lastBlock)
+	}
+	oldest := uint64(0)
+	if uint64(blockCount) <= last {
+		oldest = last - uint64(blockCount) + 1
+	}
+
+	history := &feeHistory{OldestBlock: (*hexutil.Big)(new(big.Int).SetUint64(oldest))}
+	for number := oldest; number <= last; number++ {
+		block := blockchain.GetBlockByNumber(number)
+		tips := make([]*big.Int, 0, len(block.Transactions()))
+		for _, tx := range block.Transactions() {
+			tips = append(tips, tx.EffectiveGasTipValue(block.BaseFee()))
+		}
+		sort.Slice(tips, func(i, j int) bool {
+			return tips[i].Cmp(tips[j]) < 0
+		})
+		reward := make([]*hexutil.Big, len(rewardPercentiles))
+		for i, percentile := range rewardPercentiles {
+			reward[i] = (*hexutil.Big)(big.NewInt(0))
+			if len(tips) > 0 {
+				reward[i] = (*hexutil.Big)(tips[int(percentile/100*float64(len(tips)-1))])
+			}
+		}
+		history.Reward = append(history.Reward, reward)
+		history.BaseFee = append(history.BaseFee, (*hexutil.Big)(block.BaseFee()))
+		history.GasUsedRatio = append(history.GasUsedRatio, float64(block.GasUsed())/float64(block.GasLimit()))
+	}
+	nextBaseFee := misc.CalcBaseFee(blockchain.Config(), blockchain.GetHeaderByNumber(last))
+	history.BaseFee = append(history.BaseFee, (*hexutil.Big)(nextBaseFee))
+	return history, nil
+}
+
+// SendRawTransaction sends the transaction, and mines it in a new block.
+func (api *simulatedEth) SendRawTransaction(ctx context.Context, data hexutil.Bytes) (common.Hash, error) {
+	tx := new(types.Transaction)
+	if err := tx.UnmarshalBinary(data); err != nil {
+		return common.Hash{}, err
+	}
+	if err := api.chain.backend.SendTransaction(ctx, tx); err != nil {
+		return common.Hash{}, err
+	}
+	api.chain.backend.Commit()
+	return tx.Hash(), nil
+}
+
+func (api *simulatedEth) GetTransactionByHash(ctx context.Context, hash common.Hash) (map[string]interface{}, error) {
+	tx, _, err := api.chain.backend.TransactionByHash(ctx, hash)
+	if err == ethereum.NotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	data, err := json.Marshal(tx)
+	if err != nil {
+		return nil, err
+	}
+	fields := map[string]interface{}{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	from, err := types.Sender(types.LatestSignerForChainID(tx.ChainId()), tx)
+	if err != nil {
+		return nil, err
+	}
+	fields["from"] = from
+	if receipt, err := api.chain.backend.TransactionReceipt(ctx, hash); err == nil {
+		fields["blockHash"] = receipt.BlockHash
+		fields["blockNumber"] = (*hexutil.Big)(receipt.BlockNumber)
+		fields["transactionIndex"] = hexutil.Uint64(receipt.TransactionIndex)
+	}
+	return fields, nil
+}
+
+// NewHeads publishes the headers of new blocks to the subscriber.
+func (api *simulatedEth) NewHeads(ctx context.Context) (*rpc.Subscription, error) {
+	notifier, ok := rpc.NotifierFromContext(ctx)
+	if !ok {
+		return nil, rpc.ErrNotificationsUnsupported
+	}
+	sub := notifier.CreateSubscription()
+	headers := make(chan *types.Header)
+	headSub, err := api.chain.backend.SubscribeNewHead(context.Background(), headers)
+	if err != nil {
+		return nil, err
+	}
+	go func() {
+		defer headSub.Unsubscribe()
+		for {
+			select {
+			case header := <-headers:
+				notifier.Notify(sub.ID, header)
+			case <-sub.Err():
+				return
+			}
+		}
+	}()
+	return sub, nil
+}
+
+func (api *simulatedEth) GetTransactionReceipt(ctx context.Context, hash common.Hash) (*types.Receipt, error) {
+	receipt, err := api.chain.backend.TransactionReceipt(ctx, hash)
+	if err == ethereum.NotFound || receipt == nil {
+		return nil, nil
+	}
+	return receipt, err
+}
+
+// erc20Bytecode returns the bytecode of a CompatibleERC20 token whose supply is
+// held by the deployer. The token stores its supply at slot 0, the balance of
+// an owner at keccak256(owner . 1), and the allowance of a spender at
+// keccak256(spender . keccak256(owner . 2)). Like the tokens that it is
+// compatible with, its transfers and approvals do not return a value.
+func erc20Bytecode(supply *big.Int) []byte {
+	arg := func(i int) string {
+		return fmt.Sprintf("PUSH %d\nCALLDATALOAD\n", 4+32*i)
+	}
+	slot := func(key string, mapping string) string {
+		return key + "PUSH 0\nMSTORE\n" + mapping + "PUSH 32\nMSTORE\nPUSH 64\nPUSH 0\nKECCAK256\n"
+	}
+	balance := func(owner string) string {
+		return slot(owner, "PUSH 1\n")
+	}
+	allowance := func(owner, spender string) string {
+		return slot(spender, slot(owner, "PUSH 2\n"))
+	}
+	returnWord := "PUSH 0\nMSTORE\nPUSH 32\nPUSH 0\nRETURN\n"
+	topic := func(event string) string {
+		return fmt.Sprintf("PUSH 0x%x\n", crypto.Keccak256([]byte(event)))
+	}
+
+	// move moves the amount from the balance of the owner to the balance of
+	// the recipient, and logs the transfer
+	move := func(from, to, amount string) string {
+		return balance(from) + "SLOAD\n" + amount +
+			"DUP2\nDUP2\nGT\nJUMPI @revert\nSWAP1\nSUB\n" + balance(from) + "SSTORE\n" +
+			balance(to) + "SLOAD\n" + amount + "ADD\n" + balance(to) + "SSTORE\n" +
+			amount + "PUSH 0\nMSTORE\n" + to + from + topic("Transfer(address,address,uint256)") + "PUSH 32\nPUSH 0\nLOG3\nSTOP\n"
+	}
+
+	runtime := "PUSH 0\nCALLDATALOAD\nPUSH 224\nSHR\n"
+	for _, method := range []string{"totalSupply()", "balanceOf(address)", "allowance(address,address)", "transfer(address,uint256)", "approve(address,uint256)", "transferFrom(address,address,uint256)"} {
+		name := method[:strings.Index(method, "(")]
+		runtime += fmt.Sprintf("DUP1\nPUSH 0x%x\nEQ\nJUMPI @%s\n", crypto.Keccak256([]byte(method))[:4], name)
+	}
+	runtime += "revert:\nPUSH 0\nDUP1\nREVERT\n"
+	runtime += "totalSupply:\nPUSH 0\nSLOAD\n" + returnWord
+	runtime += "balanceOf:\n" + balance(arg(0)) + "SLOAD\n" + returnWord
+	runtime += "allowance:\n" + allowance(arg(0), arg(1)) + "SLOAD\n" + returnWord
+	runtime += "transfer:\n" + move("CALLER\n", arg(0), arg(1))
+	runtime += "approve:\n" + arg(1) + allowance("CALLER\n", arg(0)) + "SSTORE\n" +
+		arg(1) + "PUSH 0\nMSTORE\n" + arg(0) + "CALLER\n" + topic("Approval(address,address,uint256)") + "PUSH 32\nPUSH 0\nLOG3\nSTOP\n"
+	runtime += "transferFrom:\n" + allowance(arg(0), "CALLER\n") + "SLOAD\n" + arg(2) +
+		"DUP2\nDUP2\nGT\nJUMPI @revert\nSWAP1\nSUB\n" + allowance(arg(0), "CALLER\n") + "SSTORE\n" +
+		move(arg(0), arg(1), arg(2))
+
+	// The constructor mints the supply to the deployer, and returns the
+	// runtime code that follows it
+	runtimeCode := compileAsm(runtime)
+	constructor := fmt.Sprintf("PUSH %v\nDUP1\nPUSH 0\nSSTORE\n", supply) + balance("CALLER\n") + "SSTORE\n" +
+		fmt.Sprintf("PUSH %d\nDUP1\nPUSH @runtime\nPUSH 1\nADD\nPUSH 0\nCODECOPY\nPUSH 0\nRETURN\nruntime:\n", len(runtimeCode))
+	return append(compileAsm(constructor), runtimeCode...)
+}
+
+// compileAsm compiles EVM assembly into bytecode.
+func compileAsm(source string) []byte {
+	compiler := asm.NewCompiler(false)
+	compiler.Feed(asm.Lex([]byte(source), false))
+	code, errs := compiler.Compile()
+	Expect(errs).Should(BeEmpty())
+	return common.FromHex(code)
+}
@@ -0,0 +1,191 @@
+package beth_test
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/republicprotocol/beth-go"
+)
+
+// fakeEth is the `eth` namespace of a node whose head is block 100, and that
+// publishes a new head every 10 milliseconds to its subscribers.
+type fakeEth struct{}
+
+func (fakeEth) ChainId() hexutil.Uint64 {
+	return 1337
+}
+
+func (fakeEth) BlockNumber() hexutil.Uint64 {
+	return 100
+}
+
+func (fakeEth) GetBlockByNumber(tag string, full bool) map[string]interface{} {
+	return map[string]interface{}{"number": hexutil.Uint64(100)}
+}
+
+func (fakeEth) GetTransactionByHash(hash common.Hash) map[string]interface{} {
+	return map[string]interface{}{"hash": hash, "blockNumber": hexutil.Uint64(42)}
+}
+
+func (fakeEth) GetBalance(addr common.Address, block string) *hexutil.Big {
+	return (*hexutil.Big)(big.NewInt(7))
+}
+
+func (fakeEth) NewHeads(ctx context.Context) (*rpc.Subscription, error) {
+	notifier, ok := rpc.NotifierFromContext(ctx)
+	if !ok {
+		return nil, rpc.ErrNotificationsUnsupported
+	}
+	sub := notifier.CreateSubscription()
+	go func() {
+		for number := int64(101); ; number++ {
+			select {
+			case <-sub.Err():
+				return
+			case <-time.After(10 * time.Millisecond):
+				header := &types.Header{Number: big.NewInt(number), Difficulty: big.NewInt(1)}
+				if notifier.Notify(sub.ID, header) != nil {
+					return
+				}
+			}
+		}
+	}()
+	return sub, nil
+}
+
+// fakeNet is the `net` namespace of a node.
+type fakeNet struct{}
+
+func (fakeNet) Version() string {
+	return "1337"
+}
+
+var _ = Describe("transports", func() {
+
+	var server *rpc.Server
+
+	BeforeEach(func() {
+		server = rpc.NewServer()
+		Expect(server.RegisterName("eth", fakeEth{})).Should(Succeed())
+		Expect(server.RegisterName("net", fakeNet{})).Should(Succeed())
+	})
+
+	AfterEach(func() {
+		server.Stop()
+	})
+
+	transports := map[string]func() (string, func()){
+		"WebSocket": func() (string, func()) {
+			httpServer := httptest.NewServer(server.WebsocketHandler([]string{"*"}))
+			return "ws://" + strings.TrimPrefix(httpServer.URL, "http://"), httpServer.Close
+		},
+		"IPC": func() (string, func()) {
+			dir, err := ioutil.TempDir("", "beth")
+			Expect(err).ShouldNot(HaveOccurred())
+			path := filepath.Join(dir, "node.ipc")
+			listener, err := net.Listen("unix", path)
+			Expect(err).ShouldNot(HaveOccurred())
+			go server.ServeListener(listener)
+			return path, func() {
+				listener.Close()
+				os.RemoveAll(dir)
+			}
+		},
+	}
+
+	for name, serve := range transports {
+		name, serve := name, serve
+
+		Context("when connected over "+name, func() {
+			var client beth.Client
+			var closeServer func()
+			var ctx context.Context
+			var cancel context.CancelFunc
+
+			BeforeEach(func() {
+				var url string
+				url, closeServer = serve()
+				var err error
+				client, err = beth.Connect(url)
+				Expect(err).ShouldNot(HaveOccurred())
+				ctx, cancel = context.WithTimeout(context.Background(), 5*time.Second)
+			})
+
+			AfterEach(func() {
+				cancel()
+				client.Close()
+				closeServer()
+			})
+
+			It("should read block numbers and balances", func() {
+				blockNumber, err := client.CurrentBlockNumber(ctx)
+				Expect(err).ShouldNot(HaveOccurred())
+				Expect(blockNumber.Int64()).Should(Equal(int64(100)))
+
+				blockNumber, err = client.TxBlockNumber(ctx, common.Hash{}.Hex())
+				Expect(err).ShouldNot(HaveOccurred())
+				Expect(blockNumber.Int64()).Should(Equal(int64(42)))
+
+				balances, err := client.BalancesOf(ctx, []common.Address{{}, {}})
+				Expect(err).ShouldNot(HaveOccurred())
+				Expect(balances).Should(Equal([]*big.Int{big.NewInt(7), big.NewInt(7)}))
+			})
+
+			It("should return the errors of the node", func() {
+				err := client.Call(ctx, nil, "eth_unknown")
+				var rpcErr *beth.RPCError
+				Expect(errors.As(err, &rpcErr)).Should(BeTrue())
+				Expect(rpcErr.Code).Should(Equal(-32601))
+
+				requests := []beth.RPCRequest{{Method: "eth_unknown"}}
+				Expect(client.BatchCall(ctx, requests)).Should(Succeed())
+				Expect(errors.As(requests[0].Err, &rpcErr)).Should(BeTrue())
+			})
+
+			It("should subscribe to new heads", func() {
+				headers := make(chan *types.Header)
+				sub, err := client.SubscribeNewHead(ctx, headers)
+				Expect(err).ShouldNot(HaveOccurred())
+				defer sub.Unsubscribe()
+
+				Eventually(headers).Should(Receive())
+			})
+
+			It("should track confirmations using new heads", func() {
+				tracker := beth.NewConfirmationTracker(client, time.Hour)
+				var last uint64
+				for count := range tracker.Track(ctx, big.NewInt(100), 3) {
+					last = count
+				}
+				Expect(last).Should(Equal(uint64(3)))
+			})
+		})
+	}
+
+	Context("when connected over HTTP", func() {
+		It("should not subscribe to new heads", func() {
+			httpServer := httptest.NewServer(newFakeChain(100))
+			defer httpServer.Close()
+			client, err := beth.Connect(httpServer.URL)
+			Expect(err).ShouldNot(HaveOccurred())
+
+			_, err = client.SubscribeNewHead(context.Background(), make(chan *types.Header))
+			Expect(err).Should(Equal(rpc.ErrNotificationsUnsupported))
+		})
+	})
+})